@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AnsibleTask struct {
+	Name  string     `yaml:"name"`
+	K8s   AnsibleK8s `yaml:"kubernetes.core.k8s"`
+	NoLog bool       `yaml:"no_log"`
+}
+
+type AnsibleK8s struct {
+	State      string  `yaml:"state"`
+	Definition *Secret `yaml:"definition"`
+}
+
+// quoteJinja returns s as single quoted Jinja string literal
+func quoteJinja(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`)
+	return "'" + replacer.Replace(s) + "'"
+}
+
+// AnsibleTasks returns a kubernetes.core.k8s task which creates the same secret as NewImagePullSecret.
+// If passwordVar is set, the docker config is built at runtime from that Ansible variable.
+func AnsibleTasks(registry, user, pass, name, namespace, passwordVar string, indent int) (string, error) {
+	if passwordVar != "" && !IsVariableNameValid(passwordVar) {
+		return "", fmt.Errorf("invalid Ansible variable name %q", passwordVar)
+	}
+
+	secret, err := NewImagePullSecret(registry, user, pass, name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	if passwordVar != "" {
+		secret.Data[DataKeyDockerConfigJSON] = fmt.Sprintf(
+			"{{ {'auths': {%s: {'username': %s, 'password': %s, 'auth': (%s ~ ':' ~ %s) | b64encode}}} | to_json | b64encode }}",
+			quoteJinja(registry),
+			quoteJinja(user),
+			passwordVar,
+			quoteJinja(user),
+			passwordVar,
+		)
+	}
+
+	tasks := []AnsibleTask{
+		{
+			Name: fmt.Sprintf("Create image pull secret %s", name),
+			K8s: AnsibleK8s{
+				State:      "present",
+				Definition: secret,
+			},
+			NoLog: true,
+		},
+	}
+
+	return toYAML(tasks, indent)
+}
+
+// AnsibleTasks returns the kubernetes.core.k8s task for the registry credentials of the secret
+func (s *Secret) AnsibleTasks(passwordVar string, indent int) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return AnsibleTasks(registry, auth.Username, auth.Password, s.Metadata.Name, s.Metadata.Namespace, passwordVar, indent)
+}
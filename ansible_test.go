@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAnsibleTasks(t *testing.T) {
+	out, err := AnsibleTasks("docker.io", "user", "pass", "mysecret", "apps", "", 0)
+	assert.NoError(t, err)
+
+	var tasks []AnsibleTask
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &tasks))
+	assert.Len(t, tasks, 1)
+	assert.True(t, tasks[0].NoLog)
+	assert.Equal(t, "present", tasks[0].K8s.State)
+
+	expected, _ := NewImagePullSecret("docker.io", "user", "pass", "mysecret", "apps")
+	assert.Equal(t, expected, tasks[0].K8s.Definition)
+}
+
+func TestAnsibleTasks_PasswordVariable(t *testing.T) {
+	out, err := AnsibleTasks("docker.io", "it's", "pass", "mysecret", "", "registry_password", 0)
+	assert.NoError(t, err)
+
+	var tasks []AnsibleTask
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &tasks))
+
+	data := tasks[0].K8s.Definition.Data[DataKeyDockerConfigJSON]
+	assert.Equal(t,
+		`{{ {'auths': {'docker.io': {'username': 'it\'s', 'password': registry_password, 'auth': ('it\'s' ~ ':' ~ registry_password) | b64encode}}} | to_json | b64encode }}`,
+		data,
+	)
+
+	_, err = AnsibleTasks("docker.io", "user", "pass", "mysecret", "", "1invalid", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// password references, everything else is taken literally
+	passwordRefEnv  = "env:"
+	passwordRefFile = "file:"
+)
+
+// BatchRow is one secret of a batch input file
+type BatchRow struct {
+	Registry string `yaml:"registry"`
+	Username string `yaml:"username"`
+	// literal password, env:VARIABLE or file:path (relative to the input file)
+	Password  string `yaml:"password"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+	// key=value pairs, comma separated in CSV files
+	Labels map[string]string `yaml:"labels"`
+	// line of the row in the input file
+	Line int `yaml:"-"`
+	// error of a malformed row, reported by RunBatch
+	err error
+}
+
+// BatchRowResult is the outcome of one batch row
+type BatchRowResult struct {
+	Line     int
+	Secret   *Secret
+	Warnings []string
+	Err      error
+}
+
+// BatchResult collects the results of all rows of a batch
+type BatchResult struct {
+	Rows []BatchRowResult
+	// how the manifests of the generated secrets are written
+	encode pullsecret.EncodeOptions
+}
+
+// BatchOptions are the defaults applied to every row of a batch
+type BatchOptions struct {
+	// name template for rows without name, see NewValidatedPullSecret
+	NameTemplate string
+	// namespace of rows without namespace
+	Namespace string
+	// labels of rows without labels
+	Labels map[string]string
+	// every secret is checked against it, nil allows everything
+	Policy *pullsecret.Policy
+	// how the manifests of the generated secrets are written
+	Encode pullsecret.EncodeOptions
+}
+
+// batchColumns maps the accepted CSV header names to the row fields
+var batchColumns = map[string]func(*BatchRow, string){
+	"registry":    func(r *BatchRow, v string) { r.Registry = v },
+	"username":    func(r *BatchRow, v string) { r.Username = v },
+	"password":    func(r *BatchRow, v string) { r.Password = v },
+	"passwordref": func(r *BatchRow, v string) { r.Password = v },
+	"name":        func(r *BatchRow, v string) { r.Name = v },
+	"namespace":   func(r *BatchRow, v string) { r.Namespace = v },
+	"labels":      func(r *BatchRow, v string) { r.Labels, r.err = pullsecret.ParseLabels(v) },
+}
+
+// ParseBatchFile reads the rows of a CSV or YAML batch file, the format is chosen by the extension
+func ParseBatchFile(fileName string, content []byte) ([]BatchRow, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return parseBatchCSV(content)
+	case ".yaml", ".yml":
+		return parseBatchYAML(content)
+	default:
+		return nil, fmt.Errorf("unsupported batch file %s, expected .csv, .yaml or .yml", fileName)
+	}
+}
+
+// parseBatchCSV expects a header row with the column names registry, username, password, name, namespace and labels
+func parseBatchCSV(content []byte) ([]BatchRow, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	// rows with a wrong number of fields are reported per row
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	setters := make([]func(*BatchRow, string), len(header))
+	for i, column := range header {
+		setter, ok := batchColumns[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return nil, fmt.Errorf("unknown CSV column %q", column)
+		}
+		setters[i] = setter
+	}
+
+	var rows []BatchRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := BatchRow{Line: line}
+
+		if len(record) != len(header) {
+			row.err = fmt.Errorf("expected %d fields, found %d", len(header), len(record))
+		} else {
+			for i, value := range record {
+				setters[i](&row, value)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseBatchYAML expects a list of rows
+func parseBatchYAML(content []byte) ([]BatchRow, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("line %d: expected a list of secrets", list.Line)
+	}
+
+	rows := make([]BatchRow, 0, len(list.Content))
+	for _, item := range list.Content {
+		var row BatchRow
+		if err := item.Decode(&row); err != nil {
+			row = BatchRow{err: err}
+		}
+		row.Line = item.Line
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ResolvePassword resolves env: and file: references, relative files are read from baseDir
+func ResolvePassword(ref, baseDir string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, passwordRefEnv):
+		variable := strings.TrimPrefix(ref, passwordRefEnv)
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", variable)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, passwordRefFile):
+		path := strings.TrimPrefix(ref, passwordRefFile)
+		if !filepath.IsAbs(path) && baseDir != "" {
+			path = filepath.Join(baseDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		return ref, nil
+	}
+}
+
+// RunBatch creates a pull secret for every row, a failing row does not stop the batch.
+// Rows without name are named by the name template, see NewValidatedPullSecret.
+func RunBatch(rows []BatchRow, baseDir string, opts BatchOptions) BatchResult {
+	result := BatchResult{Rows: make([]BatchRowResult, 0, len(rows)), encode: opts.Encode}
+	// line of the first row of every secret, the same secret twice would be overwritten on apply
+	seen := map[string]int{}
+
+	for _, row := range rows {
+		rowResult := BatchRowResult{Line: row.Line}
+
+		err := row.err
+		password := ""
+		if err == nil {
+			password, err = ResolvePassword(strings.TrimSpace(row.Password), baseDir)
+		}
+		namespace := row.Namespace
+		if strings.TrimSpace(namespace) == "" {
+			namespace = opts.Namespace
+		}
+		labels := row.Labels
+		if len(labels) == 0 {
+			labels = opts.Labels
+		}
+		if err == nil {
+			rowResult.Secret, rowResult.Warnings, err = NewValidatedPullSecret(SecretInput{
+				Registry:  row.Registry,
+				Username:  row.Username,
+				Password:  password,
+				Name:      row.Name,
+				Namespace: namespace,
+				Labels:    labels,
+
+				NameTemplate: opts.NameTemplate,
+				Policy:       opts.Policy,
+			})
+		}
+		if err == nil {
+			ref := secretRef(rowResult.Secret)
+			if line, ok := seen[ref]; ok {
+				rowResult.Secret, rowResult.Warnings = nil, nil
+				err = fmt.Errorf("duplicate secret %s, already defined in line %d", ref, line)
+			} else {
+				seen[ref] = row.Line
+			}
+		}
+		rowResult.Err = err
+
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	return result
+}
+
+// Secrets returns the secrets of all successful rows
+func (r BatchResult) Secrets() []*Secret {
+	var secrets []*Secret
+	for _, row := range r.Rows {
+		if row.Err == nil {
+			secrets = append(secrets, row.Secret)
+		}
+	}
+	return secrets
+}
+
+// Failed returns the number of rows which could not be generated
+func (r BatchResult) Failed() int {
+	failed := 0
+	for _, row := range r.Rows {
+		if row.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Report returns one line per row with the generated secret or the error
+func (r BatchResult) Report() string {
+	var b strings.Builder
+	for _, row := range r.Rows {
+		if row.Err != nil {
+			fmt.Fprintf(&b, "line %d: ERROR %v\n", row.Line, row.Err)
+			continue
+		}
+		fmt.Fprintf(&b, "line %d: OK %s\n", row.Line, secretRef(row.Secret))
+		for _, warning := range row.Warnings {
+			fmt.Fprintf(&b, "line %d: WARNING %s\n", row.Line, warning)
+		}
+	}
+	fmt.Fprintf(&b, "%d of %d secrets generated, %d failed\n", len(r.Rows)-r.Failed(), len(r.Rows), r.Failed())
+	return b.String()
+}
+
+// MultiDocumentYAML returns all generated secrets as one multi-document YAML
+func (r BatchResult) MultiDocumentYAML() (string, error) {
+	docs := []string{}
+	for _, secret := range r.Secrets() {
+		doc, err := secret.ToYAMLWith(r.encode)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, doc)
+	}
+	return joinYAMLDocuments(docs...), nil
+}
+
+// Files returns one YAML file per generated secret, the file names are namespace-name.yaml
+func (r BatchResult) Files() (map[string]string, error) {
+	files := map[string]string{}
+	for _, secret := range r.Secrets() {
+		doc, err := secret.ToYAMLWith(r.encode)
+		if err != nil {
+			return nil, err
+		}
+
+		base := secret.Metadata.Name
+		if secret.Metadata.Namespace != "" {
+			base = secret.Metadata.Namespace + "-" + base
+		}
+
+		// namespace and name may contain dashes, e.g. a-b/c and a/b-c
+		fileName := base + ".yaml"
+		for i := 2; files[fileName] != ""; i++ {
+			fileName = fmt.Sprintf("%s-%d.yaml", base, i)
+		}
+		files[fileName] = doc
+	}
+	return files, nil
+}
+
+// sortedFileNames returns the names of the files of Files in a stable order to write them
+func sortedFileNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// existingFiles returns the names which already exist in the folder
+func existingFiles(dir string, names []string) []string {
+	var existing []string
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			existing = append(existing, name)
+		}
+	}
+	return existing
+}
+
+// secretRef returns namespace/name of the secret, only the name without namespace
+func secretRef(s *Secret) string {
+	if s.Metadata.Namespace == "" {
+		return s.Metadata.Name
+	}
+	return s.Metadata.Namespace + "/" + s.Metadata.Name
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBatchFile_CSV(t *testing.T) {
+	rows, err := ParseBatchFile("secrets.csv", []byte(`registry,username,password,name,namespace
+# team a
+ghcr.io,alice,env:GHCR_TOKEN,ghcr-pull,team-a
+registry.example.com,bob,secret
+`))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, BatchRow{Registry: "ghcr.io", Username: "alice", Password: "env:GHCR_TOKEN", Name: "ghcr-pull", Namespace: "team-a", Line: 3}, rows[0])
+	assert.Equal(t, 4, rows[1].Line)
+	assert.Error(t, rows[1].err, "missing fields are reported per row")
+
+	_, err = ParseBatchFile("secrets.csv", []byte("registry,user\n"))
+	assert.Error(t, err)
+
+	_, err = ParseBatchFile("secrets.txt", nil)
+	assert.Error(t, err)
+}
+
+func TestParseBatchFile_YAML(t *testing.T) {
+	rows, err := ParseBatchFile("secrets.yaml", []byte(`- registry: ghcr.io
+  username: alice
+  password: file:ghcr.token
+  name: ghcr-pull
+  labels:
+    owner: alice
+- registry: [invalid]
+`))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "file:ghcr.token", rows[0].Password)
+	assert.Equal(t, map[string]string{"owner": "alice"}, rows[0].Labels)
+	assert.Equal(t, 1, rows[0].Line)
+	assert.Equal(t, 7, rows[1].Line)
+	assert.Error(t, rows[1].err)
+
+	_, err = ParseBatchFile("secrets.yml", []byte("registry: ghcr.io\n"))
+	assert.Error(t, err)
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ghcr.token"), []byte("from-file\n"), 0o600))
+	t.Setenv("BATCH_TEST_TOKEN", "from-env")
+
+	result := RunBatch([]BatchRow{
+		{Registry: "ghcr.io", Username: "alice", Password: "file:ghcr.token", Name: "ghcr-pull", Namespace: "team-a", Line: 2},
+		{Registry: "quay.io", Username: "bob", Password: "env:BATCH_TEST_TOKEN", Name: "Invalid_Name", Line: 3},
+		{Registry: "<region>-docker.pkg.dev", Username: "_json_key", Password: "key", Line: 4},
+		{Registry: "ghcr.io", Username: "carol", Password: "env:BATCH_TEST_UNSET", Line: 5},
+		{Registry: "docker.io", Username: "dave", Password: "pass", Name: "ghcr-pull", Namespace: "team-a", Line: 6},
+	}, dir, BatchOptions{})
+
+	assert.Len(t, result.Rows, 5)
+	assert.Equal(t, 3, result.Failed())
+	assert.Len(t, result.Secrets(), 2)
+
+	_, auth, err := result.Rows[0].Secret.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", auth.Password)
+
+	_, auth, err = result.Rows[1].Secret.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", auth.Password)
+	assert.Len(t, result.Rows[1].Warnings, 1)
+
+	assert.ErrorContains(t, result.Rows[2].Err, "placeholder")
+	assert.ErrorContains(t, result.Rows[3].Err, "BATCH_TEST_UNSET")
+	assert.ErrorContains(t, result.Rows[4].Err, "already defined in line 2")
+
+	report := result.Report()
+	assert.Contains(t, report, "line 2: OK team-a/ghcr-pull\n")
+	assert.Contains(t, report, "line 3: WARNING name is invalid: must consist of lowercase characters")
+	assert.Contains(t, report, "2 of 5 secrets generated, 3 failed\n")
+
+	multiDoc, err := result.MultiDocumentYAML()
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(multiDoc, yamlDocumentSeparator), 2)
+
+	files, err := result.Files()
+	assert.NoError(t, err)
+	assert.Contains(t, files, "team-a-ghcr-pull.yaml")
+	assert.Len(t, files, 2)
+}
+
+func TestBatchFilesExisting(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "team-a-ghcr-pull.yaml"), []byte("old"), 0o600))
+
+	names := sortedFileNames(map[string]string{"team-b-ghcr-pull.yaml": "b", "team-a-ghcr-pull.yaml": "a"})
+	assert.Equal(t, []string{"team-a-ghcr-pull.yaml", "team-b-ghcr-pull.yaml"}, names)
+	assert.Equal(t, []string{"team-a-ghcr-pull.yaml"}, existingFiles(dir, names))
+	assert.Empty(t, existingFiles(t.TempDir(), names))
+}
+
+func TestIsCLICommand(t *testing.T) {
+	assert.True(t, isCLICommand([]string{"batch", "rows.csv"}))
+	assert.True(t, isCLICommand([]string{"lint"}))
+	assert.True(t, isCLICommand([]string{"--help"}))
+	assert.False(t, isCLICommand(nil))
+	assert.False(t, isCLICommand([]string{"-psn_0_12345"}))
+	assert.False(t, isCLICommand([]string{"/home/user/secret.yaml"}))
+}
+
+func TestRunCLI_Batch(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "secrets.csv")
+	assert.NoError(t, os.WriteFile(input, []byte("registry,username,password,name,namespace\nghcr.io,alice,token,ghcr-pull,team-a\nghcr.io,,token,missing-user,team-a\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "unnamed.csv"), []byte("registry,username,password\nghcr.io,bob,token\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := runCLI([]string{"batch", input}, &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stdout.String(), "name: ghcr-pull")
+	assert.Contains(t, stderr.String(), "line 3: ERROR")
+
+	outDir := filepath.Join(dir, "out")
+	stdout.Reset()
+	stderr.Reset()
+	code = runCLI([]string{"batch", "-d", outDir, input}, &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Empty(t, stdout.String())
+	assert.FileExists(t, filepath.Join(outDir, "team-a-ghcr-pull.yaml"))
+
+	stdout.Reset()
+	stderr.Reset()
+	code = runCLI([]string{"batch", "-name-template", "{{.Username}}-pull", filepath.Join(dir, "unnamed.csv")}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), "name: bob-pull")
+
+	stdout.Reset()
+	stderr.Reset()
+	code = runCLI([]string{"batch", "-namespace", "team-b", "-indent", "4", "-string-data", filepath.Join(dir, "unnamed.csv")}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), "\n    namespace: team-b\n")
+	assert.Contains(t, stdout.String(), "\nstringData:\n    .dockerconfigjson: '{\"auths\"")
+	assert.Equal(t, exitUsage, runCLI([]string{"batch", "-indent", "0", filepath.Join(dir, "unnamed.csv")}, &stdout, &stderr))
+
+	assert.Equal(t, exitUsage, runCLI([]string{"unknown"}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLI([]string{"batch"}, &stdout, &stderr))
+}
+
+func TestRunCLI_BatchPolicy(t *testing.T) {
+	dir := t.TempDir()
+	policy := filepath.Join(dir, "policy.yaml")
+	assert.NoError(t, os.WriteFile(policy, []byte("version: 1\nforbiddenNamespaces: [default]\nrequiredLabels: [owner]\nseverity:\n  requiredLabels: warning\n"), 0o600))
+	input := filepath.Join(dir, "secrets.csv")
+	assert.NoError(t, os.WriteFile(input, []byte(`registry,username,password,name,namespace,labels
+ghcr.io,alice,token,ghcr-pull,team-a,"owner=alice,env=prod"
+ghcr.io,bob,token,ghcr-pull,default,
+ghcr.io,carol,token,carol-pull,team-a,
+ghcr.io,dave,token,dave-pull,team-a,owner
+`), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := runCLI([]string{"batch", "-policy", policy, input}, &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stdout.String(), "\n    env: prod\n    owner: alice\n")
+	assert.Contains(t, stderr.String(), "line 3: ERROR namespace is not allowed by the policy: no pull secrets in namespace default\n")
+	assert.Contains(t, stderr.String(), "line 4: WARNING labels is not allowed by the policy: label owner is required\n")
+	assert.Contains(t, stderr.String(), `line 5: ERROR labels is invalid: expected key=value, found "owner"`)
+
+	// the labels of the flag apply to rows without labels
+	stdout.Reset()
+	stderr.Reset()
+	code = runCLI([]string{"batch", "-policy", policy, "-labels", "owner=team", input}, &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.NotContains(t, stderr.String(), "WARNING")
+
+	assert.Equal(t, exitUsage, runCLI([]string{"batch", "-labels", "owner", input}, &stdout, &stderr))
+	assert.Equal(t, exitFailed, runCLI([]string{"batch", "-policy", filepath.Join(dir, "missing.yaml"), input}, &stdout, &stderr))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/javaLux/registrymate/utils"
+)
+
+const batchFileName = "image-pull-secrets.yaml"
+
+// openBatchFile generates the secrets of a CSV or YAML batch file and shows the report
+func (g *generator) openBatchFile() {
+	openDialog := dialog.NewFileOpen(
+		func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriReader.Close(); err != nil {
+					log.Printf("Batch-File - failed to close uriReader: %v", err)
+				}
+			}()
+
+			content, err := io.ReadAll(uriReader)
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+
+			rows, err := ParseBatchFile(uriReader.URI().Name(), content)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", uriReader.URI().Name(), err), g.window)
+				return
+			}
+
+			// the default labels were validated on load
+			labels, _ := pullsecret.ParseLabels(g.appSettings.DefaultLabels)
+
+			// file: password references are relative to the batch file
+			g.showBatchResult(RunBatch(rows, filepath.Dir(uriReader.URI().Path()), BatchOptions{
+				NameTemplate: g.appSettings.NameTemplate,
+				Namespace:    g.appSettings.DefaultNamespace,
+				Labels:       labels,
+				Policy:       g.appSettings.Policy(),
+				Encode:       g.appSettings.EncodeOptions(),
+			}))
+		},
+		g.window,
+	)
+
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".yaml", ".yml"}))
+	openDialog.SetTitleText("Open Batch File")
+	openDialog.Resize(fyne.NewSize(600.0, 400.0))
+	openDialog.Show()
+}
+
+// showBatchResult shows the per-row report and lets the user save the generated secrets
+func (g *generator) showBatchResult(result BatchResult) {
+	report := widget.NewLabel(result.Report())
+	report.TextStyle.Monospace = true
+
+	var resultDialog *dialog.CustomDialog
+
+	singleFileBtn := widget.NewButton("Save as one file", func() {
+		resultDialog.Hide()
+		g.saveBatchFile(result)
+	})
+	perSecretBtn := widget.NewButton("Save one file per secret", func() {
+		resultDialog.Hide()
+		g.saveBatchFolder(result)
+	})
+	if len(result.Secrets()) == 0 {
+		singleFileBtn.Disable()
+		perSecretBtn.Disable()
+	}
+
+	resultDialog = dialog.NewCustom("Batch Report", "Close",
+		container.NewBorder(nil, container.NewHBox(singleFileBtn, perSecretBtn), nil, nil,
+			container.NewScroll(report)),
+		g.window,
+	)
+	resultDialog.Resize(fyne.NewSize(700, 450))
+	resultDialog.Show()
+}
+
+// saveBatchFile saves all secrets as one multi-document YAML
+func (g *generator) saveBatchFile(result BatchResult) {
+	multiDoc, err := result.MultiDocumentYAML()
+	if err != nil {
+		dialog.ShowError(err, g.window)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(
+		func(uriWriter fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+			if uriWriter == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriWriter.Close(); err != nil {
+					log.Printf("File-Save - failed to close uriWriter: %v", err)
+				}
+			}()
+
+			originalPath := uriWriter.URI().Path()
+
+			// delete the empty file created by the dialog
+			_ = os.Remove(originalPath)
+
+			if err := utils.WriteFile(utils.EnsureYAMLExt(originalPath), []byte(multiDoc)); err != nil {
+				dialog.ShowError(err, g.window)
+			} else {
+				g.showToast(fmt.Sprintf("Saved %d secrets", len(result.Secrets())))
+			}
+		},
+		g.window,
+	)
+
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+	saveDialog.SetFileName(batchFileName)
+	saveDialog.SetTitleText("Save Image-Pull-Secrets")
+	saveDialog.Resize(fyne.NewSize(600.0, 400.0))
+	saveDialog.Show()
+}
+
+// saveBatchFolder saves every secret as its own file into the chosen folder
+func (g *generator) saveBatchFolder(result BatchResult) {
+	files, err := result.Files()
+	if err != nil {
+		dialog.ShowError(err, g.window)
+		return
+	}
+
+	folderDialog := dialog.NewFolderOpen(
+		func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+			if uri == nil {
+				// cancelled
+				return
+			}
+
+			dir := uri.Path()
+			names := sortedFileNames(files)
+			write := func() {
+				for i, name := range names {
+					if err := utils.WriteFile(filepath.Join(dir, name), []byte(files[name])); err != nil {
+						dialog.ShowError(fmt.Errorf("saved %d of %d files: %w", i, len(names), err), g.window)
+						return
+					}
+				}
+				g.showToast(fmt.Sprintf("Saved %d files", len(names)))
+			}
+
+			existing := existingFiles(dir, names)
+			if len(existing) == 0 {
+				write()
+				return
+			}
+
+			message := fmt.Sprintf("%d of the files already exist in %s:\n%s\n\nOverwrite them?",
+				len(existing), dir, strings.Join(existing, "\n"))
+			dialog.ShowConfirm("Save Image-Pull-Secrets", message, func(confirmed bool) {
+				if confirmed {
+					write()
+				}
+			}, g.window)
+		},
+		g.window,
+	)
+
+	folderDialog.SetTitleText("Save Image-Pull-Secrets")
+	folderDialog.Resize(fyne.NewSize(600.0, 400.0))
+	folderDialog.Show()
+}
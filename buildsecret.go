@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+const (
+	DefaultBuildSecretKey = "config.json"
+	DefaultBuildMountPath = "/kaniko/.docker"
+	buildVolumeName       = "docker-config"
+)
+
+// BuildSecretOptions configure the Opaque secret used by in-cluster image builds
+type BuildSecretOptions struct {
+	// data key of the docker config JSON
+	Key string
+	// mount path of the volume in the example snippet
+	MountPath string
+	// append an example volume/volumeMount snippet as comment
+	WithSnippet bool
+	// YAML indentation in spaces, pullsecret.DefaultIndent if 0
+	Indent int
+}
+
+type buildPodSnippet struct {
+	Volumes    []buildVolume    `yaml:"volumes"`
+	Containers []buildContainer `yaml:"containers"`
+}
+
+type buildVolume struct {
+	Name   string            `yaml:"name"`
+	Secret buildSecretVolume `yaml:"secret"`
+}
+
+type buildSecretVolume struct {
+	SecretName string           `yaml:"secretName"`
+	Items      []buildKeyToPath `yaml:"items"`
+}
+
+type buildKeyToPath struct {
+	Key  string `yaml:"key"`
+	Path string `yaml:"path"`
+}
+
+type buildContainer struct {
+	Name         string             `yaml:"name"`
+	VolumeMounts []buildVolumeMount `yaml:"volumeMounts"`
+}
+
+type buildVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+}
+
+// BuildSecret returns the docker config JSON of the pull secret as Opaque secret, as expected by Kaniko
+// or BuildKit to push images, optionally followed by an example volume snippet
+func (s *Secret) BuildSecret(opts BuildSecretOptions) (string, error) {
+	key := opts.Key
+	if key == "" {
+		key = DefaultBuildSecretKey
+	}
+	mountPath := opts.MountPath
+	if mountPath == "" {
+		mountPath = DefaultBuildMountPath
+	}
+
+	dockerCfgB64, ok := s.Data[DataKeyDockerConfigJSON]
+	if !ok {
+		return "", fmt.Errorf("secret contains no %s", DataKeyDockerConfigJSON)
+	}
+
+	buildSecret := Secret{
+		APIVersion: APIVersionV1,
+		Kind:       KindSecret,
+		Type:       SecretTypeOpaque,
+		Metadata: Metadata{
+			Name:      s.Metadata.Name,
+			Namespace: s.Metadata.Namespace,
+		},
+		Data: map[string]string{
+			key: dockerCfgB64,
+		},
+	}
+
+	secretYAML, err := buildSecret.ToYAMLWith(pullsecret.EncodeOptions{Indent: opts.Indent})
+	if err != nil {
+		return "", err
+	}
+
+	if !opts.WithSnippet {
+		return secretYAML, nil
+	}
+
+	snippet := buildPodSnippet{
+		Volumes: []buildVolume{
+			{
+				Name: buildVolumeName,
+				Secret: buildSecretVolume{
+					SecretName: s.Metadata.Name,
+					// the builders always read config.json, whatever the data key is
+					Items: []buildKeyToPath{{Key: key, Path: "config.json"}},
+				},
+			},
+		},
+		Containers: []buildContainer{
+			{
+				Name: "builder",
+				VolumeMounts: []buildVolumeMount{
+					{Name: buildVolumeName, MountPath: mountPath},
+				},
+			},
+		},
+	}
+
+	snippetYAML, err := toYAML(snippet, opts.Indent)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(secretYAML)
+	b.WriteString("\n# Example usage in the pod spec of the build:\n")
+	for line := range strings.SplitSeq(strings.TrimSuffix(snippetYAML, "\n"), "\n") {
+		b.WriteString("# " + line + "\n")
+	}
+
+	return b.String(), nil
+}
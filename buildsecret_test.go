@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildSecret(t *testing.T) {
+	secret, _ := NewImagePullSecret("registry.example.com", "user", "pass", "kaniko-push", "ci")
+
+	out, err := secret.BuildSecret(BuildSecretOptions{})
+	assert.NoError(t, err)
+
+	var buildSecret Secret
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &buildSecret))
+	assert.Equal(t, SecretTypeOpaque, buildSecret.Type)
+	assert.Equal(t, "kaniko-push", buildSecret.Metadata.Name)
+	assert.Equal(t, "ci", buildSecret.Metadata.Namespace)
+	assert.Equal(t, secret.Data[DataKeyDockerConfigJSON], buildSecret.Data[DefaultBuildSecretKey])
+	assert.NotContains(t, out, "#")
+}
+
+func TestBuildSecret_Snippet(t *testing.T) {
+	secret, _ := NewImagePullSecret("registry.example.com", "user", "pass", "kaniko-push", "")
+
+	out, err := secret.BuildSecret(BuildSecretOptions{Key: "docker.json", MountPath: "/root/.docker", WithSnippet: true})
+	assert.NoError(t, err)
+
+	var buildSecret Secret
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &buildSecret))
+	assert.Contains(t, buildSecret.Data, "docker.json")
+
+	// the snippet is commented out and can be parsed after removing the comment prefix
+	_, snippet, found := strings.Cut(out, "# Example usage in the pod spec of the build:\n")
+	assert.True(t, found)
+
+	var pod buildPodSnippet
+	assert.NoError(t, yaml.Unmarshal([]byte(strings.ReplaceAll(snippet, "# ", "")), &pod))
+	assert.Equal(t, "kaniko-push", pod.Volumes[0].Secret.SecretName)
+	assert.Equal(t, buildKeyToPath{Key: "docker.json", Path: "config.json"}, pod.Volumes[0].Secret.Items[0])
+	assert.Equal(t, "/root/.docker", pod.Containers[0].VolumeMounts[0].MountPath)
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+// exit codes of the command line interface
+const (
+	exitOK      = 0
+	exitFailed  = 1
+	exitUsage   = 2
+	cliFileMode = 0o600
+	cliName     = "registrymate"
+)
+
+// cliStdin is read by commands which take a password from stdin, replaced in tests
+var cliStdin io.Reader = os.Stdin
+
+// cliCommand is a subcommand of the command line interface
+type cliCommand struct {
+	name        string
+	description string
+	run         func(args []string, stdout, stderr io.Writer) int
+}
+
+var cliCommands = []cliCommand{
+	{
+		name:        "batch",
+		description: "generate pull secrets for every row of a CSV or YAML file",
+		run:         runBatchCommand,
+	},
+	{
+		name:        "lint",
+		description: "check pull secret manifests for typical faults and optionally fix them",
+		run:         runLintCommand,
+	},
+	{
+		name:        "rotate",
+		description: "replace the password of a registry user in existing pull secret manifests",
+		run:         runRotateCommand,
+	},
+}
+
+// isCLICommand reports whether the arguments start with a subcommand or a help flag
+func isCLICommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if isHelpArg(args[0]) {
+		return true
+	}
+	for _, cmd := range cliCommands {
+		if cmd.name == args[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func isHelpArg(arg string) bool {
+	return arg == "-h" || arg == "--help" || arg == "help"
+}
+
+// runCLI runs the subcommand given by the first argument and returns the exit code
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || isHelpArg(args[0]) {
+		printCLIUsage(stderr)
+		return exitUsage
+	}
+
+	for _, cmd := range cliCommands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	_, _ = fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+	printCLIUsage(stderr)
+	return exitUsage
+}
+
+func printCLIUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "Usage: %s <command> [options]\n\nStarts the GUI if no command is given.\n\nCommands:\n", cliName)
+	for _, cmd := range cliCommands {
+		_, _ = fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+}
+
+func runBatchCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	outFile := flags.String("o", "", "write all secrets as multi-document YAML to this file instead of stdout")
+	outDir := flags.String("d", "", "write one YAML file per secret to this directory")
+	nameTemplate := flags.String("name-template", "", "name template for rows without name, e.g. {{.RegistryHost}}-pull")
+	namespace := flags.String("namespace", "", "namespace for rows without namespace")
+	indent := flags.Int("indent", pullsecret.DefaultIndent, "YAML indentation in spaces")
+	stringData := flags.Bool("string-data", false, "write the docker config as plain JSON to stringData instead of base64 to data")
+	labelsFlag := flags.String("labels", "", "labels for rows without labels, e.g. owner=team-a,env=prod")
+	policyFile := flags.String("policy", "", "check every secret against this policy file, violations fail the row")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: %s batch [-o file | -d dir] [-name-template template] [-namespace namespace] [-labels labels] [-policy file] [-indent n] [-string-data] <input.csv|input.yaml>\n\n", cliName)
+		_, _ = fmt.Fprintln(stderr, "Columns: registry, username, password, name, namespace, labels.")
+		_, _ = fmt.Fprintln(stderr, "Passwords can reference env:VARIABLE or file:path (relative to the input file).")
+		_, _ = fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || (*outFile != "" && *outDir != "") || *indent < 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	labels, err := pullsecret.ParseLabels(*labelsFlag)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "-labels: %v\n", err)
+		return exitUsage
+	}
+
+	var policy *pullsecret.Policy
+	if *policyFile != "" {
+		if policy, err = pullsecret.LoadPolicy(*policyFile); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitFailed
+		}
+	}
+
+	input := flags.Arg(0)
+	content, err := os.ReadFile(input)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+
+	rows, err := ParseBatchFile(input, content)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%s: %v\n", input, err)
+		return exitFailed
+	}
+
+	result := RunBatch(rows, filepath.Dir(input), BatchOptions{
+		NameTemplate: *nameTemplate,
+		Namespace:    *namespace,
+		Labels:       labels,
+		Policy:       policy,
+		Encode:       pullsecret.EncodeOptions{Indent: *indent, StringData: *stringData},
+	})
+	_, _ = fmt.Fprint(stderr, result.Report())
+
+	if err := writeBatchResult(result, *outFile, *outDir, stdout); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+
+	if result.Failed() > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+func runLintCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	fix := flags.Bool("fix", false, "rewrite the files with the fixable findings corrected")
+	outFile := flags.String("o", "", "write the corrected manifest of a single input file to this file instead")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: %s lint [-fix | -o file] <file|dir>...\n\n", cliName)
+		_, _ = fmt.Fprintln(stderr, "Directories are searched recursively for .yaml and .yml files, documents other than Secrets are skipped.")
+		_, _ = fmt.Fprintln(stderr, "The exit code is 1 if errors remain.")
+		_, _ = fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 || (*fix && *outFile != "") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	files, err := ManifestFiles(flags.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+	if *outFile != "" && len(files) != 1 {
+		_, _ = fmt.Fprintln(stderr, "-o needs exactly one input file")
+		return exitUsage
+	}
+
+	results := LintFiles(files)
+	_, _ = fmt.Fprint(stdout, LintReport(results))
+
+	code := exitOK
+	for _, r := range results {
+		if r.Err != nil {
+			code = exitFailed
+			continue
+		}
+
+		switch {
+		case *outFile != "":
+			err = r.WriteFixed(*outFile)
+		case *fix && r.Fixable():
+			err = r.WriteFixed(r.Path)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitFailed
+		}
+
+		remaining := r.Result.Errors()
+		if *fix || *outFile != "" {
+			remaining = r.Result.Remaining()
+		}
+		if remaining > 0 {
+			code = exitFailed
+		}
+	}
+	return code
+}
+
+func runRotateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	registry := flags.String("registry", "", "registry of the entries to rotate, compared by host")
+	username := flags.String("username", "", "username of the entries to rotate")
+	passwordRef := flags.String("password", "", "env:VARIABLE or file:path of the new password, read from stdin if not set")
+	dryRun := flags.Bool("dry-run", false, "print the changes without writing the files")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: %s rotate -registry host -username name [-password env:VARIABLE|file:path] [-dry-run] <file|dir>...\n\n", cliName)
+		_, _ = fmt.Fprintln(stderr, "Directories are searched recursively for .yaml and .yml files, only the matching auths entries are rewritten.")
+		_, _ = fmt.Fprintln(stderr, "The exit code is 1 if no entry matched or a file failed.")
+		_, _ = fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 || *registry == "" || *username == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	password, err := readRotatePassword(*passwordRef)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "-password: %v\n", err)
+		return exitUsage
+	}
+
+	files, err := ManifestFiles(flags.Args())
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+
+	results, err := RotateFiles(files, *registry, *username, password)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	_, _ = fmt.Fprint(stdout, RotateReport(results))
+
+	if !*dryRun {
+		for _, r := range results {
+			if !r.Changed() {
+				continue
+			}
+			if err := r.Write(); err != nil {
+				_, _ = fmt.Fprintln(stderr, err)
+				return exitFailed
+			}
+		}
+	}
+
+	summary := SummarizeRotation(results)
+	if summary.Failed > 0 || summary.Rotated+summary.UpToDate == 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// readRotatePassword resolves an env: or file: reference or reads the first line of stdin. A literal
+// password is refused, it would end up in the shell history.
+func readRotatePassword(ref string) (string, error) {
+	if ref != "" {
+		if !strings.HasPrefix(ref, passwordRefEnv) && !strings.HasPrefix(ref, passwordRefFile) {
+			return "", fmt.Errorf("expected env:VARIABLE or file:path, a literal password would end up in the shell history")
+		}
+		return ResolvePassword(ref, "")
+	}
+
+	line, err := bufio.NewReader(cliStdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeBatchResult writes the generated secrets to a directory, a file or stdout
+func writeBatchResult(result BatchResult, outFile, outDir string, stdout io.Writer) error {
+	if len(result.Secrets()) == 0 {
+		return nil
+	}
+
+	if outDir != "" {
+		files, err := result.Files()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			return err
+		}
+
+		for _, name := range sortedFileNames(files) {
+			if err := os.WriteFile(filepath.Join(outDir, name), []byte(files[name]), cliFileMode); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	multiDoc, err := result.MultiDocumentYAML()
+	if err != nil {
+		return err
+	}
+
+	if outFile != "" {
+		return os.WriteFile(outFile, []byte(multiDoc), cliFileMode)
+	}
+
+	_, err = io.WriteString(stdout, multiDoc)
+	return err
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+const (
+	ecrModeProfile    = "Profile"
+	ecrModeAccessKeys = "Access Keys"
+	ecrRequestTimeout = 30 * time.Second
+)
+
+// fetchECRToken requests an authorization token from ECR and fills in the registry credentials
+func (g *generator) fetchECRToken() {
+	profileEntry := widget.NewEntry()
+	profileEntry.SetPlaceHolder("default")
+	profileEntry.SetText(g.formatOption(optECRProfile))
+
+	accessKeyEntry := widget.NewEntry()
+	accessKeyEntry.SetPlaceHolder("AKIA...")
+	secretKeyEntry := widget.NewPasswordEntry()
+	sessionTokenEntry := widget.NewPasswordEntry()
+	sessionTokenEntry.SetPlaceHolder("optional")
+
+	modeRadio := widget.NewRadioGroup([]string{ecrModeProfile, ecrModeAccessKeys}, func(mode string) {
+		if mode == ecrModeAccessKeys {
+			profileEntry.Disable()
+			accessKeyEntry.Enable()
+			secretKeyEntry.Enable()
+			sessionTokenEntry.Enable()
+		} else {
+			profileEntry.Enable()
+			accessKeyEntry.Disable()
+			secretKeyEntry.Disable()
+			sessionTokenEntry.Disable()
+		}
+	})
+	modeRadio.Horizontal = true
+	modeRadio.Required = true
+	modeRadio.SetSelected(ecrModeProfile)
+
+	// the region of the registry host has precedence over the stored one
+	regionEntry := widget.NewEntry()
+	regionEntry.SetPlaceHolder("e.g. eu-central-1 (profile region if empty)")
+	regionEntry.SetText(g.formatOption(optECRRegion))
+	if _, region, ok := utils.ParseECRHost(strings.TrimSpace(g.regEntry.Text)); ok {
+		regionEntry.SetText(region)
+	}
+
+	endpointEntry := widget.NewEntry()
+	endpointEntry.SetPlaceHolder("optional, https://api.ecr.<region>.amazonaws.com if empty")
+	endpointEntry.SetText(g.formatOption(optECREndpoint))
+	endpointEntry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if u, err := url.Parse(s); err != nil || u.Host == "" {
+			return fmt.Errorf("invalid endpoint URL")
+		}
+		return nil
+	}
+
+	form := dialog.NewForm("Fetch Amazon ECR Token", "Fetch", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Credentials", modeRadio),
+			widget.NewFormItem("Profile", profileEntry),
+			widget.NewFormItem("Access Key ID", accessKeyEntry),
+			widget.NewFormItem("Secret Access Key", secretKeyEntry),
+			widget.NewFormItem("Session Token", sessionTokenEntry),
+			widget.NewFormItem("Region", regionEntry),
+			widget.NewFormItem("Endpoint", endpointEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			// only the profile name is stored, never the access keys
+			g.setFormatOption(optECRProfile, profileEntry.Text)
+			g.setFormatOption(optECRRegion, regionEntry.Text)
+			g.setFormatOption(optECREndpoint, endpointEntry.Text)
+
+			region := strings.TrimSpace(regionEntry.Text)
+			var creds utils.AWSCredentials
+			if modeRadio.Selected == ecrModeAccessKeys {
+				creds = utils.AWSCredentials{
+					AccessKeyID:     strings.TrimSpace(accessKeyEntry.Text),
+					SecretAccessKey: strings.TrimSpace(secretKeyEntry.Text),
+					SessionToken:    strings.TrimSpace(sessionTokenEntry.Text),
+				}
+				if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+					dialog.ShowError(fmt.Errorf("access key ID and secret access key are required"), g.window)
+					return
+				}
+			} else {
+				profileCreds, profileRegion, err := utils.LoadAWSProfile(strings.TrimSpace(profileEntry.Text))
+				if err != nil {
+					dialog.ShowError(err, g.window)
+					return
+				}
+				creds = profileCreds
+				if region == "" {
+					region = profileRegion
+				}
+			}
+
+			g.requestECRToken(strings.TrimSpace(endpointEntry.Text), region, creds)
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(560, 460))
+	form.Show()
+}
+
+// requestECRToken calls ECR in the background and fills in the entries once the token arrives
+func (g *generator) requestECRToken(endpoint, region string, creds utils.AWSCredentials) {
+	g.fetchTokenBtn.Disable()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), ecrRequestTimeout)
+		defer cancel()
+
+		token, err := utils.GetECRAuthorizationToken(ctx, nil, endpoint, region, creds)
+
+		fyne.Do(func() {
+			if g.currentPreset().FetchToken {
+				g.fetchTokenBtn.Enable()
+			}
+
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+
+			if host := strings.TrimPrefix(token.ProxyEndpoint, "https://"); host != "" {
+				g.regEntry.SetText(host)
+			}
+			g.userEntry.SetText(token.Username)
+			g.passEntry.SetText(token.Password)
+			g.showToast(fmt.Sprintf("ECR token valid until %s", token.ExpiresAt.Local().Format("2006-01-02 15:04")))
+		})
+	}()
+}
+
+func (g *generator) ecrRefreshOptions(onSaved func()) {
+	regionEntry := widget.NewEntry()
+	regionEntry.SetPlaceHolder("optional, taken from the registry host if empty")
+	regionEntry.SetText(g.formatOption(optECRRegion))
+
+	scheduleEntry := widget.NewEntry()
+	scheduleEntry.SetPlaceHolder(DefaultECRRefreshSchedule)
+	scheduleEntry.SetText(g.formatOption(optECRSchedule))
+	scheduleEntry.Validator = func(s string) error {
+		if s != "" && len(strings.Fields(s)) != 5 {
+			return fmt.Errorf("the schedule needs five cron fields")
+		}
+		return nil
+	}
+
+	credentialsSecretEntry := widget.NewEntry()
+	credentialsSecretEntry.SetPlaceHolder("<name>" + awsCredentialsSuffix)
+	credentialsSecretEntry.SetText(g.formatOption(optECRCredentialsSecret))
+	credentialsSecretEntry.Validator = func(s string) error {
+		if s != "" && !utils.IsK8sNameValid(s) {
+			return fmt.Errorf("invalid K8s name")
+		}
+		return nil
+	}
+
+	form := dialog.NewForm("ECR Refresh Options", "Save", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("AWS Region", regionEntry),
+			widget.NewFormItem("Schedule", scheduleEntry),
+			widget.NewFormItem("AWS Credentials Secret", credentialsSecretEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			g.setFormatOption(optECRRegion, regionEntry.Text)
+			g.setFormatOption(optECRSchedule, scheduleEntry.Text)
+			g.setFormatOption(optECRCredentialsSecret, credentialsSecretEntry.Text)
+			onSaved()
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(520, 280))
+	form.Show()
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/javaLux/registrymate/utils"
+)
+
+const (
+	APIVersionRBAC  = "rbac.authorization.k8s.io/v1"
+	APIVersionBatch = "batch/v1"
+
+	KindServiceAccount = "ServiceAccount"
+	KindRole           = "Role"
+	KindRoleBinding    = "RoleBinding"
+	KindCronJob        = "CronJob"
+
+	// tokens expire after 12 hours, refreshing every 6 hours tolerates one failed run
+	DefaultECRRefreshSchedule = "0 */6 * * *"
+	// the init container needs a shell, base64 and tr besides the AWS CLI
+	DefaultAWSCLIImage = "public.ecr.aws/aws-cli/aws-cli:2.27.0"
+	// the init container writes the secret, so the kubectl image needs no shell
+	DefaultKubectlImage = "registry.k8s.io/kubectl:v1.34.1"
+
+	ecrRefreshSuffix        = "-ecr-refresh"
+	awsCredentialsSuffix    = "-aws-credentials"
+	ecrTokenVolumeName      = "ecr-token"
+	ecrTokenMountPath       = "/ecr"
+	ecrSecretFile           = ecrTokenMountPath + "/secret.json"
+	defaultRefreshNamespace = "default"
+)
+
+// ECRRefreshOptions configure the CronJob which refreshes an ECR pull secret in-cluster
+type ECRRefreshOptions struct {
+	// AWS region, taken from the registry host if empty
+	Region string
+	// cron schedule of the refresh
+	Schedule string
+	// name of the secret with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, "<name>-aws-credentials" if empty
+	CredentialsSecret string
+	AWSCLIImage       string
+	KubectlImage      string
+	// YAML indentation in spaces, pullsecret.DefaultIndent if 0
+	Indent int
+}
+
+type ServiceAccount struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+}
+
+type Role struct {
+	APIVersion string       `yaml:"apiVersion"`
+	Kind       string       `yaml:"kind"`
+	Metadata   Metadata     `yaml:"metadata"`
+	Rules      []PolicyRule `yaml:"rules"`
+}
+
+type PolicyRule struct {
+	APIGroups     []string `yaml:"apiGroups"`
+	Resources     []string `yaml:"resources"`
+	ResourceNames []string `yaml:"resourceNames,omitempty"`
+	Verbs         []string `yaml:"verbs"`
+}
+
+type RoleBinding struct {
+	APIVersion string    `yaml:"apiVersion"`
+	Kind       string    `yaml:"kind"`
+	Metadata   Metadata  `yaml:"metadata"`
+	RoleRef    RoleRef   `yaml:"roleRef"`
+	Subjects   []Subject `yaml:"subjects"`
+}
+
+type RoleRef struct {
+	APIGroup string `yaml:"apiGroup"`
+	Kind     string `yaml:"kind"`
+	Name     string `yaml:"name"`
+}
+
+type Subject struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type CronJob struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   Metadata    `yaml:"metadata"`
+	Spec       CronJobSpec `yaml:"spec"`
+}
+
+type CronJobSpec struct {
+	Schedule          string          `yaml:"schedule"`
+	ConcurrencyPolicy string          `yaml:"concurrencyPolicy"`
+	JobTemplate       JobTemplateSpec `yaml:"jobTemplate"`
+}
+
+type JobTemplateSpec struct {
+	Spec JobSpec `yaml:"spec"`
+}
+
+type JobSpec struct {
+	BackoffLimit int             `yaml:"backoffLimit"`
+	Template     PodTemplateSpec `yaml:"template"`
+}
+
+type PodTemplateSpec struct {
+	Spec PodSpec `yaml:"spec"`
+}
+
+type PodSpec struct {
+	ServiceAccountName string      `yaml:"serviceAccountName"`
+	RestartPolicy      string      `yaml:"restartPolicy"`
+	InitContainers     []Container `yaml:"initContainers"`
+	Containers         []Container `yaml:"containers"`
+	Volumes            []Volume    `yaml:"volumes"`
+}
+
+type Container struct {
+	Name         string        `yaml:"name"`
+	Image        string        `yaml:"image"`
+	Command      []string      `yaml:"command"`
+	EnvFrom      []EnvFrom     `yaml:"envFrom,omitempty"`
+	VolumeMounts []VolumeMount `yaml:"volumeMounts"`
+}
+
+type EnvFrom struct {
+	SecretRef LocalObjectReference `yaml:"secretRef"`
+}
+
+type LocalObjectReference struct {
+	Name string `yaml:"name"`
+}
+
+type VolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+}
+
+type Volume struct {
+	Name     string   `yaml:"name"`
+	EmptyDir struct{} `yaml:"emptyDir"`
+}
+
+// ECRRefreshManifests returns the pull secret together with a ServiceAccount, Role, RoleBinding and CronJob
+// which replace the secret with a fresh ECR token before the current one expires
+func (s *Secret) ECRRefreshManifests(opts ECRRefreshOptions) (string, error) {
+	registry, _, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	region := opts.Region
+	if region == "" {
+		_, hostRegion, ok := utils.ParseECRHost(registry)
+		if !ok {
+			return "", fmt.Errorf("%s is no ECR registry, set the AWS region in the format options", registry)
+		}
+		region = hostRegion
+	}
+
+	name := s.Metadata.Name
+	namespace := defaultNamespace(s.Metadata.Namespace, defaultRefreshNamespace)
+	refreshName := name + ecrRefreshSuffix
+	credentialsSecret := valueOrDefault(opts.CredentialsSecret, name+awsCredentialsSuffix)
+	schedule := valueOrDefault(opts.Schedule, DefaultECRRefreshSchedule)
+	awsImage := valueOrDefault(opts.AWSCLIImage, DefaultAWSCLIImage)
+	kubectlImage := valueOrDefault(opts.KubectlImage, DefaultKubectlImage)
+
+	metadata := Metadata{Name: refreshName, Namespace: namespace}
+
+	secret := *s
+	secret.Metadata.Namespace = namespace
+
+	serviceAccount := ServiceAccount{
+		APIVersion: APIVersionV1,
+		Kind:       KindServiceAccount,
+		Metadata:   metadata,
+	}
+
+	role := Role{
+		APIVersion: APIVersionRBAC,
+		Kind:       KindRole,
+		Metadata:   metadata,
+		Rules: []PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: []string{name},
+				Verbs:         []string{"get", "patch", "update"},
+			},
+			{
+				// create can not be restricted by resource name
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"create"},
+			},
+		},
+	}
+
+	roleBinding := RoleBinding{
+		APIVersion: APIVersionRBAC,
+		Kind:       KindRoleBinding,
+		Metadata:   metadata,
+		RoleRef: RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     KindRole,
+			Name:     refreshName,
+		},
+		Subjects: []Subject{
+			{Kind: KindServiceAccount, Name: refreshName, Namespace: namespace},
+		},
+	}
+
+	tokenMount := []VolumeMount{{Name: ecrTokenVolumeName, MountPath: ecrTokenMountPath}}
+
+	cronJob := CronJob{
+		APIVersion: APIVersionBatch,
+		Kind:       KindCronJob,
+		Metadata:   metadata,
+		Spec: CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: "Forbid",
+			JobTemplate: JobTemplateSpec{
+				Spec: JobSpec{
+					BackoffLimit: 3,
+					Template: PodTemplateSpec{
+						Spec: PodSpec{
+							ServiceAccountName: refreshName,
+							RestartPolicy:      "OnFailure",
+							InitContainers: []Container{
+								{
+									Name:         "get-login-password",
+									Image:        awsImage,
+									Command:      []string{"sh", "-c", ecrSecretScript(region, registry, name, namespace)},
+									EnvFrom:      []EnvFrom{{SecretRef: LocalObjectReference{Name: credentialsSecret}}},
+									VolumeMounts: tokenMount,
+								},
+							},
+							Containers: []Container{
+								{
+									Name:         "update-secret",
+									Image:        kubectlImage,
+									Command:      []string{"kubectl", "apply", "-f", ecrSecretFile},
+									VolumeMounts: tokenMount,
+								},
+							},
+							Volumes: []Volume{{Name: ecrTokenVolumeName}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	docs := make([]string, 0, 5)
+	for _, manifest := range []any{secret, serviceAccount, role, roleBinding, cronJob} {
+		doc, err := toYAML(manifest, opts.Indent)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, doc)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# The CronJob reads the AWS credentials from the secret %s, create it with:\n", credentialsSecret)
+	fmt.Fprintf(&b, "# kubectl create secret generic %s --namespace %s --from-literal=AWS_ACCESS_KEY_ID=... --from-literal=AWS_SECRET_ACCESS_KEY=...\n",
+		credentialsSecret, namespace)
+	b.WriteString(joinYAMLDocuments(docs...))
+
+	return b.String(), nil
+}
+
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// ecrSecretScript fetches a new ECR token and writes the pull secret with it as JSON manifest to the shared
+// volume, the kubectl container only applies it
+func ecrSecretScript(region, registry, name, namespace string) string {
+	dockerConfig := `{"auths":{"%s":{"username":"%s","password":"%s","auth":"%s"}}}`
+	manifest := `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"%s","namespace":"%s"},` +
+		`"type":"` + pullsecret.TypeDockerConfigJSON + `","data":{"` + pullsecret.DataKey + `":"%s"}}`
+
+	return strings.Join([]string{
+		"token=$(aws ecr get-login-password --region " + QuotePOSIX(region) + ")",
+		"auth=$(printf '%s:%s' " + utils.ECRUsername + ` "$token" | base64 | tr -d '\n')`,
+		"config=$(printf " + QuotePOSIX(dockerConfig) + " " + QuotePOSIX(registry) + " " + utils.ECRUsername +
+			` "$token" "$auth" | base64 | tr -d '\n')`,
+		"printf " + QuotePOSIX(manifest) + " " + QuotePOSIX(name) + " " + QuotePOSIX(namespace) + ` "$config" > ` + ecrSecretFile,
+	}, " && ")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestECRRefreshManifests(t *testing.T) {
+	secret, _ := NewImagePullSecret("123456789012.dkr.ecr.eu-central-1.amazonaws.com", "AWS", "token", "ecr-pull", "apps")
+
+	out, err := secret.ECRRefreshManifests(ECRRefreshOptions{})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "# The CronJob reads the AWS credentials from the secret ecr-pull-aws-credentials"))
+
+	docs := strings.Split(out, yamlDocumentSeparator)
+	assert.Len(t, docs, 5)
+
+	var pullSecret Secret
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[0]), &pullSecret))
+	assert.Equal(t, "ecr-pull", pullSecret.Metadata.Name)
+	assert.Equal(t, secret.Data, pullSecret.Data)
+
+	var role Role
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[2]), &role))
+	assert.Equal(t, KindRole, role.Kind)
+	assert.Equal(t, []string{"ecr-pull"}, role.Rules[0].ResourceNames)
+
+	var roleBinding RoleBinding
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[3]), &roleBinding))
+	assert.Equal(t, Subject{Kind: KindServiceAccount, Name: "ecr-pull-ecr-refresh", Namespace: "apps"}, roleBinding.Subjects[0])
+
+	var cronJob CronJob
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[4]), &cronJob))
+	assert.Equal(t, DefaultECRRefreshSchedule, cronJob.Spec.Schedule)
+
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, "ecr-pull-ecr-refresh", pod.ServiceAccountName)
+	assert.Equal(t, "ecr-pull-aws-credentials", pod.InitContainers[0].EnvFrom[0].SecretRef.Name)
+	assert.Contains(t, pod.InitContainers[0].Command[2], "--region eu-central-1")
+	assert.Equal(t, []string{"kubectl", "apply", "-f", ecrSecretFile}, pod.Containers[0].Command)
+	assert.Equal(t, DefaultAWSCLIImage, pod.InitContainers[0].Image)
+	assert.Equal(t, DefaultKubectlImage, pod.Containers[0].Image)
+}
+
+func TestECRSecretScript(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell available")
+	}
+
+	path := filepath.Join(t.TempDir(), "secret.json")
+	script := ecrSecretScript("eu-central-1", "123456789012.dkr.ecr.eu-central-1.amazonaws.com", "ecr-pull", "apps")
+	// a stub of the AWS CLI, the kubectl container applies the written file
+	script = "aws() { printf 'ecr-token'; }; " + strings.ReplaceAll(script, ecrSecretFile, QuotePOSIX(path))
+	out, err := exec.Command(sh, "-c", script).CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	secret, err := pullsecret.Parse(content)
+	assert.NoError(t, err)
+	assert.NoError(t, secret.Validate())
+	assert.Equal(t, "ecr-pull", secret.Metadata.Name)
+	assert.Equal(t, "apps", secret.Metadata.Namespace)
+
+	registry, entry, err := secret.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012.dkr.ecr.eu-central-1.amazonaws.com", registry)
+	assert.Equal(t, "AWS", entry.Username)
+	assert.Equal(t, "ecr-token", entry.Password)
+}
+
+func TestECRRefreshManifests_Options(t *testing.T) {
+	secret, _ := NewImagePullSecret("registry.example.com", "AWS", "token", "ecr-pull", "")
+
+	_, err := secret.ECRRefreshManifests(ECRRefreshOptions{})
+	assert.Error(t, err, "the region can not be derived from a non-ECR host")
+
+	out, err := secret.ECRRefreshManifests(ECRRefreshOptions{
+		Region:            "us-east-1",
+		Schedule:          "0 */4 * * *",
+		CredentialsSecret: "aws",
+	})
+	assert.NoError(t, err)
+
+	docs := strings.Split(out, yamlDocumentSeparator)
+	var cronJob CronJob
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[4]), &cronJob))
+	assert.Equal(t, "0 */4 * * *", cronJob.Spec.Schedule)
+	assert.Equal(t, defaultRefreshNamespace, cronJob.Metadata.Namespace)
+	assert.Equal(t, "aws", cronJob.Spec.JobTemplate.Spec.Template.Spec.InitContainers[0].EnvFrom[0].SecretRef.Name)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	APIVersionExternalSecrets = "external-secrets.io/v1"
+	KindExternalSecret        = "ExternalSecret"
+	KindSecretStore           = "SecretStore"
+	KindClusterSecretStore    = "ClusterSecretStore"
+	DefaultRefreshInterval    = "1h"
+
+	// keys of the fetched values inside the ExternalSecret template
+	esoUsernameKey = "username"
+	esoPasswordKey = "password"
+)
+
+type ExternalSecret struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   Metadata           `yaml:"metadata"`
+	Spec       ExternalSecretSpec `yaml:"spec"`
+}
+
+type ExternalSecretSpec struct {
+	RefreshInterval string               `yaml:"refreshInterval"`
+	SecretStoreRef  SecretStoreRef       `yaml:"secretStoreRef"`
+	Target          ExternalSecretTarget `yaml:"target"`
+	Data            []ExternalSecretData `yaml:"data"`
+}
+
+type SecretStoreRef struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"`
+}
+
+type ExternalSecretTarget struct {
+	Name           string                 `yaml:"name"`
+	CreationPolicy string                 `yaml:"creationPolicy"`
+	Template       ExternalSecretTemplate `yaml:"template"`
+}
+
+type ExternalSecretTemplate struct {
+	Type          string            `yaml:"type"`
+	EngineVersion string            `yaml:"engineVersion"`
+	Data          map[string]string `yaml:"data"`
+}
+
+type ExternalSecretData struct {
+	SecretKey string    `yaml:"secretKey"`
+	RemoteRef RemoteRef `yaml:"remoteRef"`
+}
+
+type RemoteRef struct {
+	Key      string `yaml:"key"`
+	Property string `yaml:"property,omitempty"`
+}
+
+// ExternalSecretOptions describes where the External Secrets Operator fetches the credentials from
+type ExternalSecretOptions struct {
+	StoreName string
+	StoreKind string
+	// remote key of the username, if empty the username of the form is written into the template
+	UsernameKey      string
+	UsernameProperty string
+	PasswordKey      string
+	PasswordProperty string
+	RefreshInterval  string
+}
+
+// NewExternalSecret creates an ExternalSecret which lets the External Secrets Operator build
+// a kubernetes.io/dockerconfigjson secret from the given remote keys
+func NewExternalSecret(registry, user, name, namespace string, opts ExternalSecretOptions) (*ExternalSecret, error) {
+	if strings.TrimSpace(opts.StoreName) == "" {
+		return nil, fmt.Errorf("secret store name is required")
+	}
+	if strings.TrimSpace(opts.PasswordKey) == "" {
+		return nil, fmt.Errorf("remote key for the password is required")
+	}
+
+	storeKind := opts.StoreKind
+	if storeKind == "" {
+		storeKind = KindClusterSecretStore
+	}
+	if storeKind != KindSecretStore && storeKind != KindClusterSecretStore {
+		return nil, fmt.Errorf("invalid secret store kind %q", storeKind)
+	}
+
+	refreshInterval := opts.RefreshInterval
+	if refreshInterval == "" {
+		refreshInterval = DefaultRefreshInterval
+	}
+
+	// the username is either fetched as well or a fixed string. A fixed one is a quoted string inside the
+	// template actions, so template delimiters in it are not executed.
+	usernameRef := fmt.Sprintf(".%s", esoUsernameKey)
+	var data []ExternalSecretData
+
+	if opts.UsernameKey != "" {
+		data = append(data, ExternalSecretData{
+			SecretKey: esoUsernameKey,
+			RemoteRef: RemoteRef{Key: opts.UsernameKey, Property: opts.UsernameProperty},
+		})
+	} else {
+		usernameRef = strconv.Quote(user)
+	}
+
+	data = append(data, ExternalSecretData{
+		SecretKey: esoPasswordKey,
+		RemoteRef: RemoteRef{Key: opts.PasswordKey, Property: opts.PasswordProperty},
+	})
+
+	// the registry is a quoted string inside a template action as well
+	dockerConfigTemplate := fmt.Sprintf(
+		`{"auths":{ {{ %s | toJson }}:{"username":{{ %s | toJson }},"password":{{ .%s | toJson }},"auth":{{ printf "%%s:%%s" %s .%s | b64enc | toJson }}}}}`,
+		strconv.Quote(registry),
+		usernameRef,
+		esoPasswordKey,
+		usernameRef,
+		esoPasswordKey,
+	)
+
+	externalSecret := ExternalSecret{
+		APIVersion: APIVersionExternalSecrets,
+		Kind:       KindExternalSecret,
+		Metadata: Metadata{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: ExternalSecretSpec{
+			RefreshInterval: refreshInterval,
+			SecretStoreRef: SecretStoreRef{
+				Name: opts.StoreName,
+				Kind: storeKind,
+			},
+			Target: ExternalSecretTarget{
+				Name:           name,
+				CreationPolicy: "Owner",
+				Template: ExternalSecretTemplate{
+					Type:          SecretTypeDockerConfigJSON,
+					EngineVersion: "v2",
+					Data: map[string]string{
+						DataKeyDockerConfigJSON: dockerConfigTemplate,
+					},
+				},
+			},
+			Data: data,
+		},
+	}
+
+	return &externalSecret, nil
+}
+
+// ToYAML converts the ExternalSecret struct to a YAML string with the given indentation.
+func (e *ExternalSecret) ToYAML(indent int) (string, error) {
+	return toYAML(e, indent)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// renderESOTemplate renders the dockerconfigjson template like the External Secrets Operator would
+func renderESOTemplate(t *testing.T, tmpl string, values map[string]string) DockerConfig {
+	funcs := template.FuncMap{
+		"toJson": func(v any) string {
+			b, _ := json.Marshal(v)
+			return string(b)
+		},
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+	}
+
+	parsed, err := template.New("eso").Funcs(funcs).Parse(tmpl)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, parsed.Execute(&buf, values))
+
+	var cfg DockerConfig
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &cfg), buf.String())
+	return cfg
+}
+
+func TestNewExternalSecret(t *testing.T) {
+	es, err := NewExternalSecret("registry.gitlab.com", "robot$ci", "mysecret", "apps", ExternalSecretOptions{
+		StoreName:        "vault",
+		UsernameKey:      "registry/gitlab",
+		UsernameProperty: "user",
+		PasswordKey:      "registry/gitlab",
+		PasswordProperty: "token",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, KindExternalSecret, es.Kind)
+	assert.Equal(t, "mysecret", es.Metadata.Name)
+	assert.Equal(t, "apps", es.Metadata.Namespace)
+	assert.Equal(t, "mysecret", es.Spec.Target.Name)
+	assert.Equal(t, KindClusterSecretStore, es.Spec.SecretStoreRef.Kind)
+	assert.Equal(t, DefaultRefreshInterval, es.Spec.RefreshInterval)
+	assert.Equal(t, SecretTypeDockerConfigJSON, es.Spec.Target.Template.Type)
+	assert.Len(t, es.Spec.Data, 2)
+	assert.Equal(t, RemoteRef{Key: "registry/gitlab", Property: "token"}, es.Spec.Data[1].RemoteRef)
+
+	cfg := renderESOTemplate(t, es.Spec.Target.Template.Data[DataKeyDockerConfigJSON], map[string]string{
+		"username": "robot$ci",
+		"password": `pa"ss`,
+	})
+	entry := cfg.Auths["registry.gitlab.com"]
+	assert.Equal(t, "robot$ci", entry.Username)
+	assert.Equal(t, `pa"ss`, entry.Password)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`robot$ci:pa"ss`)), entry.Auth)
+
+	yamlStr, err := es.ToYAML(0)
+	assert.NoError(t, err)
+	assert.Contains(t, yamlStr, "apiVersion: external-secrets.io/v1")
+	assert.Contains(t, yamlStr, "type: kubernetes.io/dockerconfigjson")
+}
+
+func TestNewExternalSecret_LiteralUsername(t *testing.T) {
+	es, err := NewExternalSecret("docker.io", `us"er`, "mysecret", "", ExternalSecretOptions{
+		StoreName:   "aws",
+		StoreKind:   KindSecretStore,
+		PasswordKey: "docker/token",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, es.Spec.Data, 1)
+	assert.Equal(t, KindSecretStore, es.Spec.SecretStoreRef.Kind)
+
+	cfg := renderESOTemplate(t, es.Spec.Target.Template.Data[DataKeyDockerConfigJSON], map[string]string{
+		"password": "secret",
+	})
+	entry := cfg.Auths["docker.io"]
+	assert.Equal(t, `us"er`, entry.Username)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`us"er:secret`)), entry.Auth)
+
+	// template delimiters in the username are kept as text
+	es, err = NewExternalSecret("docker.io", `{{ .password }}}}`, "mysecret", "", ExternalSecretOptions{
+		StoreName:   "aws",
+		PasswordKey: "docker/token",
+	})
+	assert.NoError(t, err)
+	cfg = renderESOTemplate(t, es.Spec.Target.Template.Data[DataKeyDockerConfigJSON], map[string]string{
+		"password": "secret",
+	})
+	entry = cfg.Auths["docker.io"]
+	assert.Equal(t, `{{ .password }}}}`, entry.Username)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{{ .password }}}}:secret`)), entry.Auth)
+}
+
+func TestNewExternalSecret_LiteralRegistry(t *testing.T) {
+	registry := `reg{{ .password }}"}}`
+	es, err := NewExternalSecret(registry, "user", "mysecret", "", ExternalSecretOptions{
+		StoreName:   "aws",
+		PasswordKey: "docker/token",
+	})
+	assert.NoError(t, err)
+
+	cfg := renderESOTemplate(t, es.Spec.Target.Template.Data[DataKeyDockerConfigJSON], map[string]string{
+		"password": "secret",
+	})
+	assert.Len(t, cfg.Auths, 1)
+	entry, ok := cfg.Auths[registry]
+	assert.True(t, ok, "the registry is kept as text")
+	assert.Equal(t, "secret", entry.Password)
+}
+
+func TestNewExternalSecret_Invalid(t *testing.T) {
+	_, err := NewExternalSecret("docker.io", "user", "mysecret", "", ExternalSecretOptions{PasswordKey: "key"})
+	assert.Error(t, err)
+
+	_, err = NewExternalSecret("docker.io", "user", "mysecret", "", ExternalSecretOptions{StoreName: "store"})
+	assert.Error(t, err)
+
+	_, err = NewExternalSecret("docker.io", "user", "mysecret", "", ExternalSecretOptions{StoreName: "store", StoreKind: "Vault", PasswordKey: "key"})
+	assert.Error(t, err)
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,19 +17,27 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
 	"github.com/javaLux/registrymate/ui"
 	"github.com/javaLux/registrymate/utils"
 )
 
-const DefaultOutputText = "Nothing to show yet..."
+const (
+	DefaultOutputText   = "Nothing to show yet..."
+	DefaultOutputHeader = "Secret"
+)
 
 type generator struct {
 	appSettings            *utils.AppSettings
 	regEntry               *widget.SelectEntry
-	userEntry              *widget.Entry
+	userEntry              *widget.SelectEntry
 	passEntry              *widget.Entry
 	nameSpaceEntry         *widget.SelectEntry
+	namespacePickerBtn     *widget.Button
+	nameTemplateBtn        *widget.Button
+	namespaceManifestsChk  *widget.Check
 	nameEntry              *widget.SelectEntry
+	labelsEntry            *widget.Entry
 	aboutBtn               *widget.Button
 	generateBtn            *widget.Button
 	clearRegEntryBtn       *widget.Button
@@ -35,15 +45,37 @@ type generator struct {
 	clearPassEntryBtn      *widget.Button
 	clearNameSpaceEntryBtn *widget.Button
 	clearNameEntryBtn      *widget.Button
+	clearLabelsEntryBtn    *widget.Button
 	clearOutputBtn         *widget.Button
-	clearHistoryBtn        *widget.Button
+	historyBtn             *widget.Button
+	settingsBtn            *widget.Button
+	batchBtn               *widget.Button
+	lintBtn                *widget.Button
+	rotateBtn              *widget.Button
+	profilesBtn            *widget.Button
 	decodeBtn              *widget.Button
 	saveBtn                *widget.Button
 	copyBtn                *widget.Button
 	themeBtn               *widget.Button
+	formatOptionsBtn       *widget.Button
+	formatSelect           *widget.Select
+	presetSelect           *widget.Select
+	presetHint             *widget.Label
+	policyHint             *widget.Label
+	keyFileBtn             *widget.Button
+	fetchTokenBtn          *widget.Button
 	output                 *widget.Label
+	outputHeader           *canvas.Text
+	outputHint             *widget.Label
 	secret                 *Secret
+	credStore              utils.CredentialStore
+	namespaces             []string
+	namespaceNames         map[string]string // names of the copies if the name template depends on the namespace
 	window                 fyne.Window
+	historyWindow          fyne.Window
+	settingsWindow         fyne.Window
+	lintWindow             fyne.Window
+	rotateWindow           fyne.Window
 	isDecoded              bool
 	toast                  *ui.ToastPopup
 }
@@ -67,6 +99,7 @@ func (g *generator) loadUI(app fyne.App) {
 		g.appSettings.Width = w.Content().Size().Width
 		g.appSettings.Height = w.Content().Size().Height
 		g.appSettings.SaveAppSettings(app)
+		g.closeCredentialStore()
 	})
 
 	// globally key event handler to generate secret
@@ -88,6 +121,21 @@ func (g *generator) loadUI(app fyne.App) {
 	// --- Buttons ---
 	g.buildButtons()
 
+	// --- Output Formats ---
+	g.buildFormatSelect()
+
+	// --- Registry Presets ---
+	g.buildPresetSelect()
+
+	// --- Policy ---
+	g.buildPolicyHint()
+
+	// --- Defaults ---
+	// filled in after all widgets exist, the entries update each other on changes
+	g.nameSpaceEntry.SetText(g.appSettings.DefaultNamespace)
+	g.labelsEntry.SetText(g.appSettings.DefaultLabels)
+	g.checkPolicy()
+
 	// --- Toast Popup---
 	g.toast = ui.NewToastPopup(ui.BlueTextColor, g.window.Canvas())
 
@@ -96,6 +144,13 @@ func (g *generator) loadUI(app fyne.App) {
 	// close all remaining windows when the main window is closed
 	g.window.SetMaster()
 	g.window.Show()
+
+	if err := g.appSettings.TeamDefaultsError(); err != nil {
+		dialog.ShowError(fmt.Errorf("the team defaults were not loaded: %w", err), g.window)
+	}
+	if err := g.appSettings.PolicyError(); err != nil {
+		dialog.ShowError(fmt.Errorf("the policy was not loaded, secrets are not checked: %w", err), g.window)
+	}
 }
 
 func (g *generator) buildLabels() {
@@ -122,8 +177,7 @@ func (g *generator) buildButtons() {
 
 	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
 		if g.output.Text != DefaultOutputText {
-			fyne.CurrentApp().Clipboard().SetContent(g.output.Text)
-			g.toast.ShowToast("Copied", 2*time.Second)
+			g.copyToClipboard(g.output.Text)
 		}
 	})
 	copyBtn.Disable() // initially disabled until a secret is generated
@@ -131,10 +185,13 @@ func (g *generator) buildButtons() {
 	clearOutputBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), g.clearOutput)
 	clearOutputBtn.Disable() // initially disabled until a secret is generated
 
-	clearHistoryBtn := widget.NewButtonWithIcon("", theme.HistoryIcon(), g.clearHistory)
-	if g.appSettings.History.IsEmpty() {
-		clearHistoryBtn.Disable()
-	}
+	historyBtn := widget.NewButtonWithIcon("", theme.HistoryIcon(), g.openHistoryManager)
+	settingsBtn := widget.NewButtonWithIcon("", theme.SettingsIcon(), g.openSettings)
+
+	batchBtn := widget.NewButtonWithIcon("Batch", theme.ListIcon(), g.openBatchFile)
+	lintBtn := widget.NewButtonWithIcon("Lint", theme.SearchIcon(), g.openLint)
+	rotateBtn := widget.NewButtonWithIcon("Rotate", theme.ViewRefreshIcon(), g.openRotate)
+	profilesBtn := widget.NewButtonWithIcon("Profiles", theme.AccountIcon(), g.openProfiles)
 
 	clearRegEntryBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() { g.regEntry.SetText("") })
 	clearUserEntryBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() { g.userEntry.SetText("") })
@@ -142,6 +199,9 @@ func (g *generator) buildButtons() {
 
 	clearNameEntryBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() { g.nameEntry.SetText("") })
 	clearNameSpaceEntryBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() { g.nameSpaceEntry.SetText("") })
+	clearLabelsEntryBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() { g.labelsEntry.SetText("") })
+	namespacePickerBtn := widget.NewButtonWithIcon("", theme.ListIcon(), g.pickNamespaces)
+	nameTemplateBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), g.editNameTemplate)
 
 	var themeBtnIcon fyne.Resource
 
@@ -164,21 +224,41 @@ func (g *generator) buildButtons() {
 	g.clearPassEntryBtn = clearPassEntryBtn
 	g.clearNameEntryBtn = clearNameEntryBtn
 	g.clearNameSpaceEntryBtn = clearNameSpaceEntryBtn
-	g.clearHistoryBtn = clearHistoryBtn
+	g.clearLabelsEntryBtn = clearLabelsEntryBtn
+	g.namespacePickerBtn = namespacePickerBtn
+	g.nameTemplateBtn = nameTemplateBtn
+	g.historyBtn = historyBtn
+	g.settingsBtn = settingsBtn
+	g.batchBtn = batchBtn
+	g.lintBtn = lintBtn
+	g.rotateBtn = rotateBtn
+	g.profilesBtn = profilesBtn
 	g.themeBtn = themeBtn
 }
 
 func (g *generator) buildEntries() {
 	regEntry := widget.NewSelectEntry(g.appSettings.History.SortedRegistries())
 	regEntry.SetPlaceHolder("Registry (e.g. registry.gitlab.com)")
-	regEntry.OnChanged = func(s string) { g.canGenerate() }
+	regEntry.OnChanged = func(s string) {
+		g.updateLinkedEntries()
+		g.canGenerate()
+		g.checkPolicy()
+	}
 	regEntry.OnSubmitted = func(string) {
 		if g.isRequiredInputFilled() {
 			g.buildSecret()
 		}
 	}
+	regEntry.AlwaysShowValidationError = true
+	regEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return firstPolicyError(g.appSettings.Policy().CheckRegistry(strings.TrimSpace(s)))
+	}
 
-	userEntry := widget.NewEntry()
+	// filled with the usernames of the chosen registry
+	userEntry := widget.NewSelectEntry(nil)
 	userEntry.SetPlaceHolder("Username")
 	userEntry.OnChanged = func(string) { g.canGenerate() }
 	userEntry.OnSubmitted = func(string) {
@@ -198,6 +278,7 @@ func (g *generator) buildEntries() {
 
 	nameEntry := widget.NewSelectEntry(g.appSettings.History.SortedNames())
 	nameEntry.SetPlaceHolder("Secret-Name (optional)")
+	nameEntry.OnChanged = func(string) { g.checkPolicy() }
 	nameEntry.OnSubmitted = func(string) {
 		if g.isRequiredInputFilled() {
 			g.buildSecret()
@@ -208,14 +289,18 @@ func (g *generator) buildEntries() {
 		if s == "" {
 			return nil
 		}
-		if !utils.IsK8sNameValid(s) {
-			return fmt.Errorf("invalid K8s secret name")
+		if err := pullsecret.ValidateName(s); err != nil {
+			return err
 		}
-		return nil
+		return firstPolicyError(g.appSettings.Policy().CheckName(s))
 	}
 
 	nameSpaceEntry := widget.NewSelectEntry(g.appSettings.History.SortedNamespaces())
-	nameSpaceEntry.SetPlaceHolder("Namespace (optional)")
+	nameSpaceEntry.SetPlaceHolder("Namespaces, comma separated (optional)")
+	nameSpaceEntry.OnChanged = func(string) {
+		g.updateLinkedEntries()
+		g.checkPolicy()
+	}
 	nameSpaceEntry.OnSubmitted = func(string) {
 		if g.isRequiredInputFilled() {
 			g.buildSecret()
@@ -223,44 +308,77 @@ func (g *generator) buildEntries() {
 	}
 	nameSpaceEntry.AlwaysShowValidationError = true
 	nameSpaceEntry.Validator = func(s string) error {
-		if s == "" {
-			return nil
+		namespaces := utils.ParseNamespaces(s)
+		for _, namespace := range namespaces {
+			if err := pullsecret.ValidateNamespace(namespace); err != nil {
+				return err
+			}
+		}
+		return firstPolicyError(namespaceViolations(g.appSettings.Policy(), namespaces))
+	}
+
+	labelsEntry := widget.NewEntry()
+	labelsEntry.SetPlaceHolder("Labels, e.g. owner=team-a, env=prod (optional)")
+	labelsEntry.OnChanged = func(string) { g.checkPolicy() }
+	labelsEntry.OnSubmitted = func(string) {
+		if g.isRequiredInputFilled() {
+			g.buildSecret()
 		}
-		if !utils.IsK8sNameValid(s) {
-			return fmt.Errorf("invalid K8s namespace")
+	}
+	labelsEntry.AlwaysShowValidationError = true
+	labelsEntry.Validator = func(s string) error {
+		labels, err := pullsecret.ParseLabels(s)
+		if err != nil {
+			return err
 		}
-		return nil
+		return firstPolicyError(g.appSettings.Policy().CheckLabels(labels))
 	}
 
+	namespaceManifestsChk := widget.NewCheck("Add Namespace manifests", func(checked bool) {
+		g.setFormatOption(optNamespaceManifests, strconv.FormatBool(checked))
+		g.onFormatChanged(g.formatSelect.Selected)
+	})
+	// set the state directly, the callback needs the format select which does not exist yet
+	namespaceManifestsChk.Checked = g.formatOption(optNamespaceManifests) == "true"
+
 	g.regEntry = regEntry
 	g.userEntry = userEntry
 	g.passEntry = passEntry
 	g.nameEntry = nameEntry
 	g.nameSpaceEntry = nameSpaceEntry
+	g.labelsEntry = labelsEntry
+	g.namespaceManifestsChk = namespaceManifestsChk
 }
 
 func (g *generator) buildLayout() fyne.CanvasObject {
 	// Theme toggle button at the top right corner
-	topLayout := container.NewHBox(g.clearHistoryBtn, layout.NewSpacer(), g.aboutBtn, g.themeBtn)
+	topLayout := container.NewHBox(g.historyBtn, g.batchBtn, g.lintBtn, g.rotateBtn, g.profilesBtn, layout.NewSpacer(), g.settingsBtn, g.aboutBtn, g.themeBtn)
 
 	// Registry input with clear buttons
 	regEntryContainer := container.NewBorder(nil, nil, nil, g.clearRegEntryBtn, g.regEntry)
 	userEntryContainer := container.NewBorder(nil, nil, nil, g.clearUserEntryBtn, g.userEntry)
 	passEntryContainer := container.NewBorder(nil, nil, nil, g.clearPassEntryBtn, g.passEntry)
+	presetContainer := container.NewBorder(nil, nil, nil, container.NewHBox(g.keyFileBtn, g.fetchTokenBtn), g.presetSelect)
 	registryInput := widget.NewCard("Registry", "",
 		container.NewVBox(
+			presetContainer,
 			regEntryContainer,
 			userEntryContainer,
 			passEntryContainer,
+			g.presetHint,
 		))
 
 	// Secret-Metadata input with clear buttons
-	nameEntryContainer := container.NewBorder(nil, nil, nil, g.clearNameEntryBtn, g.nameEntry)
-	nameSpaceEntryContainer := container.NewBorder(nil, nil, nil, g.clearNameSpaceEntryBtn, g.nameSpaceEntry)
+	nameEntryContainer := container.NewBorder(nil, nil, nil, container.NewHBox(g.nameTemplateBtn, g.clearNameEntryBtn), g.nameEntry)
+	nameSpaceEntryContainer := container.NewBorder(nil, nil, nil, container.NewHBox(g.namespacePickerBtn, g.clearNameSpaceEntryBtn), g.nameSpaceEntry)
+	labelsEntryContainer := container.NewBorder(nil, nil, nil, g.clearLabelsEntryBtn, g.labelsEntry)
 	metadataInput := widget.NewCard("Metadata", "",
 		container.NewVBox(
 			nameEntryContainer,
 			nameSpaceEntryContainer,
+			labelsEntryContainer,
+			g.namespaceManifestsChk,
+			g.policyHint,
 		))
 
 	// Combine registry and metadata inputs side by side
@@ -270,6 +388,8 @@ func (g *generator) buildLayout() fyne.CanvasObject {
 	buttonContainer := widget.NewCard("", "",
 		container.NewHBox(
 			g.generateBtn,
+			g.formatSelect,
+			g.formatOptionsBtn,
 			layout.NewSpacer(),
 			g.decodeBtn,
 			g.saveBtn,
@@ -278,19 +398,30 @@ func (g *generator) buildLayout() fyne.CanvasObject {
 		))
 
 	// YAML header
-	yamlHeader := canvas.NewText("Secret", nil)
+	yamlHeader := canvas.NewText(DefaultOutputHeader, nil)
 	yamlHeader.TextSize = theme.Size(theme.SizeNameHeadingText)
 	yamlHeader.Alignment = fyne.TextAlignCenter
 	yamlHeader.TextStyle.Bold = true
+	g.outputHeader = yamlHeader
+
+	// warning of the output format, e.g. that GitLab can not mask the value in every case
+	g.outputHint = widget.NewLabel("")
+	g.outputHint.Wrapping = fyne.TextWrapWord
+	g.outputHint.Importance = widget.WarningImportance
+	g.outputHint.Hide()
 
-	// Output area with horizontal scroll
-	outputScroll := container.NewHScroll(g.output)
+	// Output area with scrolling in both directions, multi-document output can be long
+	outputScroll := container.NewScroll(g.output)
 
-	mainContainer := container.NewVBox(
-		topLayout,
-		inputContainer,
-		buttonContainer,
-		yamlHeader,
+	mainContainer := container.NewBorder(
+		container.NewVBox(
+			topLayout,
+			inputContainer,
+			buttonContainer,
+			yamlHeader,
+			g.outputHint,
+		),
+		nil, nil, nil,
 		outputScroll,
 	)
 
@@ -298,51 +429,109 @@ func (g *generator) buildLayout() fyne.CanvasObject {
 }
 
 func (g *generator) buildSecret() {
+	// invalid namespaces are dropped, the entry already shows them as invalid
+	var namespaces []string
+	for _, namespace := range utils.ParseNamespaces(g.nameSpaceEntry.Text) {
+		if utils.IsK8sNamespaceValid(namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
 
-	secretName := strings.TrimSpace(g.nameEntry.Text)
-	secretNamespace := strings.TrimSpace(g.nameSpaceEntry.Text)
+	// the secret itself is created for the first namespace, further ones are rendered as copies
+	firstNamespace := ""
+	if len(namespaces) > 0 {
+		firstNamespace = namespaces[0]
+	}
 
-	if !utils.IsK8sNameValid(secretName) {
-		// set a random generated secret name
-		secretName = utils.GeneratePullSecretName()
+	labels, err := pullsecret.ParseLabels(g.labelsEntry.Text)
+	if err == nil && len(namespaces) > 1 {
+		// the first namespace is checked together with the secret
+		err = namespaceViolations(g.appSettings.Policy(), namespaces[1:]).Err()
 	}
 
-	if !utils.IsK8sNameValid(secretNamespace) {
-		// clear invalid namespace
-		secretNamespace = ""
+	// an invalid name is replaced silently, the entry already shows it as invalid.
+	// Warnings of the policy are shown below the metadata.
+	var secret *Secret
+	if err == nil {
+		secret, _, err = NewValidatedPullSecret(SecretInput{
+			Registry:     g.regEntry.Text,
+			Username:     g.userEntry.Text,
+			Password:     g.passEntry.Text,
+			Name:         g.nameEntry.Text,
+			Namespace:    firstNamespace,
+			Labels:       labels,
+			NameTemplate: g.appSettings.NameTemplate,
+			Policy:       g.appSettings.Policy(),
+		})
 	}
 
-	secret, err := NewImagePullSecret(
-		strings.TrimSpace(g.regEntry.Text),
-		strings.TrimSpace(g.userEntry.Text),
-		strings.TrimSpace(g.passEntry.Text),
-		secretName,
-		secretNamespace,
-	)
+	// the name template was rendered for the first namespace only, the copies get their own names.
+	// A failing template was replaced by a generated name already.
+	var names map[string]string
+	if err == nil && secret.Metadata.Name != strings.TrimSpace(g.nameEntry.Text) {
+		names, _ = namespaceNames(g.appSettings.NameTemplate, g.regEntry.Text, g.userEntry.Text, namespaces)
+		for _, name := range names {
+			if err == nil {
+				err = g.appSettings.Policy().CheckName(name).Err()
+			}
+		}
+	}
 
 	if err != nil {
 		dialog.ShowError(err, g.window)
+		g.focusInvalidField(err)
 		g.saveBtn.Disable()
 		return
 	}
 
-	if yaml, err := secret.ToYAML(); err != nil {
+	// a new secret is always shown encoded first
+	g.secret = secret
+	g.namespaces = namespaces
+	g.namespaceNames = names
+	g.isDecoded = false
+	g.decodeBtn.SetIcon(theme.VisibilityOffIcon())
+
+	if err := g.renderOutput(); err != nil {
 		dialog.ShowError(err, g.window)
-		g.decodeBtn.Disable()
-		g.saveBtn.Disable()
-		g.copyBtn.Disable()
-		g.clearOutputBtn.Disable()
+		g.clearOutput()
 		return
-	} else {
-		g.secret = secret
-		g.output.SetText(yaml)
-		g.window.Canvas().Refresh(g.output)
-		g.decodeBtn.Enable()
-		g.saveBtn.Enable()
-		g.copyBtn.Enable()
-		g.clearOutputBtn.Enable()
-		g.storeHistory()
-		g.updateEntries()
+	}
+
+	g.storeHistory()
+	g.updateEntries()
+
+	if g.appSettings.CopyOnGenerate {
+		g.copyToClipboard(g.output.Text)
+	}
+}
+
+// focusInvalidField focuses the entry of a validation error or policy violation of the pullsecret package
+func (g *generator) focusInvalidField(err error) {
+	var field string
+	var verr *pullsecret.ValidationError
+	var violation pullsecret.Violation
+	switch {
+	case errors.As(err, &verr):
+		field = verr.Field
+	case errors.As(err, &violation):
+		field = violation.Field
+	default:
+		return
+	}
+
+	switch field {
+	case pullsecret.FieldRegistry:
+		g.window.Canvas().Focus(g.regEntry)
+	case pullsecret.FieldUsername:
+		g.window.Canvas().Focus(g.userEntry)
+	case pullsecret.FieldPassword:
+		g.window.Canvas().Focus(g.passEntry)
+	case pullsecret.FieldName:
+		g.window.Canvas().Focus(g.nameEntry)
+	case pullsecret.FieldNamespace:
+		g.window.Canvas().Focus(g.nameSpaceEntry)
+	case pullsecret.FieldLabels:
+		g.window.Canvas().Focus(g.labelsEntry)
 	}
 }
 
@@ -362,52 +551,39 @@ func (g *generator) canGenerate() {
 
 // Resets the output area and disables related buttons
 func (g *generator) clearOutput() {
+	g.secret = nil
+	g.namespaces = nil
+	g.namespaceNames = nil
 	g.output.SetText(DefaultOutputText)
+	g.outputHeader.Text = DefaultOutputHeader
+	g.outputHeader.Refresh()
+	g.outputHint.Hide()
 	g.decodeBtn.Disable()
 	g.saveBtn.Disable()
 	g.copyBtn.Disable()
 	g.clearOutputBtn.Disable()
 }
 
-// Clears the history and updates the entries
-func (g *generator) clearHistory() {
-	dialog.ShowConfirm("Clear History", "Do you really want to delete the history?", func(confirmed bool) {
-		if confirmed {
-			g.appSettings.History.Clear()
-			g.updateEntries()
-			g.clearHistoryBtn.Disable()
-			g.toast.ShowToast("History Cleared", 3*time.Second)
-		}
-	}, g.window)
-}
-
+// Toggles the output between the encoded and the decoded view
 func (g *generator) decodeOrEncodeSecret() {
 	if g.secret != nil {
+		g.isDecoded = !g.isDecoded
+		if err := g.renderOutput(); err != nil {
+			g.isDecoded = !g.isDecoded
+			dialog.ShowError(err, g.window)
+			return
+		}
 		if g.isDecoded {
-			// back to encoded state
-			yaml, err := g.secret.ToYAML()
-			if err != nil {
-				dialog.ShowError(err, g.window)
-				return
-			}
-			g.output.SetText(yaml)
-			g.decodeBtn.SetIcon(theme.VisibilityOffIcon())
-		} else {
-			// show decoded state
-			decoded, err := g.secret.DecodeDockerConfig()
-			if err != nil {
-				dialog.ShowError(err, g.window)
-				return
-			}
-			g.output.SetText(decoded)
 			g.decodeBtn.SetIcon(theme.VisibilityIcon())
+		} else {
+			g.decodeBtn.SetIcon(theme.VisibilityOffIcon())
 		}
-		g.isDecoded = !g.isDecoded
 	}
 }
 
 func (g *generator) saveDialog() {
 	if g.output.Text != DefaultOutputText {
+		format := g.currentFormat()
 		saveDialog := dialog.NewFileSave(
 			func(uriWriter fyne.URIWriteCloser, err error) {
 				if err != nil {
@@ -431,25 +607,29 @@ func (g *generator) saveDialog() {
 				// delete the empty file created by the dialog
 				_ = os.Remove(originalPath)
 
-				finalPath := utils.EnsureYAMLExt(originalPath)
+				finalPath := utils.EnsureExt(originalPath, format.extensions...)
 
 				if err := utils.WriteFile(finalPath, []byte(g.output.Text)); err != nil {
 					dialog.ShowError(err, g.window)
 				} else {
-					g.toast.ShowToast("Saved", 2*time.Second)
+					g.showToast("Saved")
 				}
 			},
 			g.window,
 		)
 
-		// Only allow .yaml and .yml files to select
+		// Only allow the file extensions of the current output format to select
 		saveDialog.SetFilter(
-			storage.NewExtensionFileFilter([]string{".yaml", ".yml"}),
+			storage.NewExtensionFileFilter(format.extensions),
 		)
 
 		// Set default file name
-		saveDialog.SetFileName("image-pull-secret.yaml")
-		saveDialog.SetTitleText("Save Image-Pull-Secret")
+		saveDialog.SetFileName(format.fileName)
+		if format.fanOut && len(g.namespaces) > 1 {
+			saveDialog.SetTitleText(fmt.Sprintf("Save %d Image-Pull-Secrets", len(g.namespaces)))
+		} else {
+			saveDialog.SetTitleText("Save Image-Pull-Secret")
+		}
 		saveDialog.Resize(fyne.NewSize(600.0, 400.0))
 		saveDialog.Show()
 	}
@@ -470,16 +650,65 @@ func (g *generator) changeTheme() {
 // store current entries to history
 func (g *generator) storeHistory() {
 	g.appSettings.History.AddRegistry(g.regEntry.Text)
-	g.appSettings.History.AddNamespace(g.nameSpaceEntry.Text)
+	for _, namespace := range g.namespaces {
+		g.appSettings.History.AddNamespace(namespace)
+	}
 	g.appSettings.History.AddSecretName(g.nameEntry.Text)
-	if g.clearHistoryBtn.Disabled() {
-		g.clearHistoryBtn.Enable()
+
+	// remember which values belong together, the password is never stored
+	namespaces := g.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	for _, namespace := range namespaces {
+		g.appSettings.History.AddLink(g.regEntry.Text, g.userEntry.Text, g.nameEntry.Text, namespace)
 	}
 }
 
 // update entries with latest history
 func (g *generator) updateEntries() {
 	g.regEntry.SetOptions(g.appSettings.History.SortedRegistries())
-	g.nameSpaceEntry.SetOptions(g.appSettings.History.SortedNamespaces())
-	g.nameEntry.SetOptions(g.appSettings.History.SortedNames())
+	g.updateLinkedEntries()
+}
+
+// updateLinkedEntries puts the values used together with the current registry and namespace in front of the dropdowns
+func (g *generator) updateLinkedEntries() {
+	history := g.appSettings.History
+	registry := g.regEntry.Text
+
+	g.userEntry.SetOptions(history.RegistryUsernames(registry))
+	g.nameSpaceEntry.SetOptions(utils.MergeSuggestions(history.RegistryNamespaces(registry), history.SortedNamespaces()))
+
+	names := history.RegistryNames(registry)
+	if namespaces := utils.ParseNamespaces(g.nameSpaceEntry.Text); len(namespaces) > 0 {
+		names = history.NamespaceNames(namespaces[0], registry)
+	}
+	g.nameEntry.SetOptions(utils.MergeSuggestions(names, history.SortedNames()))
+}
+
+// showToast shows a notification for the configured duration
+func (g *generator) showToast(message string) {
+	g.toast.ShowToast(message, g.appSettings.ToastDuration())
+}
+
+// copyToClipboard copies the text and clears it again after the configured time, unless something
+// else was copied in the meantime
+func (g *generator) copyToClipboard(text string) {
+	clipboard := fyne.CurrentApp().Clipboard()
+	clipboard.SetContent(text)
+
+	seconds := g.appSettings.ClipboardClearSeconds
+	if seconds <= 0 {
+		g.showToast("Copied")
+		return
+	}
+
+	g.showToast(fmt.Sprintf("Copied, cleared in %d s", seconds))
+	time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+		fyne.Do(func() {
+			if clipboard.Content() == text {
+				clipboard.SetContent("")
+			}
+		})
+	})
 }
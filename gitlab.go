@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// GitLab masks variables whose value matches this pattern
+	gitLabMaskRegex = regexp.MustCompile(`^[a-zA-Z0-9_+=/@:.~-]{8,}$`)
+	// variables with "Expand variable reference" disabled only need a single line without spaces
+	gitLabMaskRawRegex = regexp.MustCompile(`^\S{8,}$`)
+)
+
+// DockerAuthConfig returns the compact docker config JSON for the GitLab Runner DOCKER_AUTH_CONFIG variable
+func (s *Secret) DockerAuthConfig() (string, error) {
+	dockerCfgJSON, err := base64.StdEncoding.DecodeString(s.Data[DataKeyDockerConfigJSON])
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, dockerCfgJSON); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// GitLabMaskWarning checks the value against the masking rules of GitLab CI/CD variables
+// and returns a warning if it cannot be masked as is, otherwise an empty string
+func GitLabMaskWarning(value string) string {
+	switch {
+	case gitLabMaskRegex.MatchString(value):
+		return ""
+	case gitLabMaskRawRegex.MatchString(value):
+		return "The value contains characters GitLab cannot mask in expanded variables. " +
+			"Disable \"Expand variable reference\" for the DOCKER_AUTH_CONFIG variable to mask it."
+	case len(value) < 8:
+		return fmt.Sprintf("The value is only %d characters long, GitLab can only mask values with 8 or more characters.", len(value))
+	default:
+		return "The value contains whitespace or line breaks, GitLab cannot mask it. " +
+			"Remove spaces from the username and password or protect the variable in another way."
+	}
+}
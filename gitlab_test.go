@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerAuthConfig(t *testing.T) {
+	secret, _ := NewImagePullSecret("registry.gitlab.com", "user", "pass", "mysecret", "")
+
+	value, err := secret.DockerAuthConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"auths":{"registry.gitlab.com":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`, value)
+
+	var cfg DockerConfig
+	assert.NoError(t, json.Unmarshal([]byte(value), &cfg))
+}
+
+func TestGitLabMaskWarning(t *testing.T) {
+	// maskable as is
+	assert.Empty(t, GitLabMaskWarning("glpat-abcdefgh1234"))
+	assert.Empty(t, GitLabMaskWarning("dXNlcjpwYXNz=="))
+
+	// JSON can only be masked without variable expansion
+	assert.Contains(t, GitLabMaskWarning(`{"auths":{}}`), "Expand variable reference")
+
+	// too short or with whitespace it cannot be masked at all
+	assert.Contains(t, GitLabMaskWarning("short"), "8 or more")
+	assert.Contains(t, GitLabMaskWarning(`{"auths":{"a b":{}}}`), "whitespace")
+}
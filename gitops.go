@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/javaLux/registrymate/utils"
+)
+
+const (
+	APIVersionFluxSource = "source.toolkit.fluxcd.io/v1"
+	KindOCIRepository    = "OCIRepository"
+	KindHelmRepository   = "HelmRepository"
+	DefaultFluxInterval  = "5m"
+	DefaultFluxTag       = "latest"
+	FluxNamespace        = "flux-system"
+
+	ArgoCDNamespace       = "argocd"
+	ArgoCDSecretTypeLabel = "argocd.argoproj.io/secret-type"
+	ArgoCDSecretTypeRepo  = "repository"
+
+	ociScheme             = "oci://"
+	yamlDocumentSeparator = "---\n"
+	// Argo CD stores OCI Helm repositories as type helm with enableOCI
+	argoCDRepositoryType = "helm"
+	// Flux HelmRepository type for charts in OCI registries
+	fluxHelmRepositoryType = "oci"
+)
+
+type FluxSource struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   Metadata       `yaml:"metadata"`
+	Spec       FluxSourceSpec `yaml:"spec"`
+}
+
+type FluxSourceSpec struct {
+	Type      string        `yaml:"type,omitempty"`
+	Interval  string        `yaml:"interval"`
+	URL       string        `yaml:"url"`
+	Ref       *FluxOCIRef   `yaml:"ref,omitempty"`
+	SecretRef FluxSecretRef `yaml:"secretRef"`
+}
+
+type FluxOCIRef struct {
+	Tag string `yaml:"tag"`
+}
+
+type FluxSecretRef struct {
+	Name string `yaml:"name"`
+}
+
+// OCIRepositoryOptions describe the OCI repository the credentials are used for
+type OCIRepositoryOptions struct {
+	// path of the repository below the registry host, e.g. "charts/podinfo"
+	Path string
+	// Flux reconcile interval
+	Interval string
+	// Flux OCIRepository tag
+	Tag string
+	// YAML indentation in spaces, pullsecret.DefaultIndent if 0
+	Indent int
+}
+
+// ociRepositoryURL returns the repository location without scheme, e.g. "ghcr.io/org/charts"
+func ociRepositoryURL(registry, repoPath string) string {
+	host := strings.TrimSuffix(registry, "/")
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+
+	repoPath = strings.Trim(repoPath, "/")
+	if repoPath == "" {
+		return host
+	}
+
+	return host + "/" + repoPath
+}
+
+// sourceName returns the name of the Flux source, the last path element if it is a valid name
+func sourceName(repoPath, fallback string) string {
+	if name := path.Base(strings.Trim(repoPath, "/")); utils.IsK8sNameValid(name) {
+		return name
+	}
+	return fallback
+}
+
+func defaultNamespace(namespace, fallback string) string {
+	if namespace == "" {
+		return fallback
+	}
+	return namespace
+}
+
+// joinYAMLDocuments combines several YAML documents into one multi-document YAML
+func joinYAMLDocuments(docs ...string) string {
+	return strings.Join(docs, yamlDocumentSeparator)
+}
+
+// NewArgoCDRepositorySecret creates the Opaque repository secret Argo CD uses for Helm charts in an OCI registry
+func NewArgoCDRepositorySecret(registry, user, pass, name, namespace string, opts OCIRepositoryOptions) *Secret {
+	return &Secret{
+		APIVersion: APIVersionV1,
+		Kind:       KindSecret,
+		Type:       SecretTypeOpaque,
+		Metadata: Metadata{
+			Name:      name,
+			Namespace: defaultNamespace(namespace, ArgoCDNamespace),
+			Labels: map[string]string{
+				ArgoCDSecretTypeLabel: ArgoCDSecretTypeRepo,
+			},
+		},
+		StringData: map[string]string{
+			"name":      name,
+			"type":      argoCDRepositoryType,
+			"url":       ociRepositoryURL(registry, opts.Path),
+			"enableOCI": "true",
+			"username":  user,
+			"password":  pass,
+		},
+	}
+}
+
+// NewFluxSource creates an OCIRepository or HelmRepository which references the pull secret
+func NewFluxSource(kind, registry, secretName, namespace string, opts OCIRepositoryOptions) (*FluxSource, error) {
+	interval := opts.Interval
+	if interval == "" {
+		interval = DefaultFluxInterval
+	}
+
+	spec := FluxSourceSpec{
+		Interval:  interval,
+		URL:       ociScheme + ociRepositoryURL(registry, opts.Path),
+		SecretRef: FluxSecretRef{Name: secretName},
+	}
+
+	switch kind {
+	case KindOCIRepository:
+		tag := opts.Tag
+		if tag == "" {
+			tag = DefaultFluxTag
+		}
+		spec.Ref = &FluxOCIRef{Tag: tag}
+	case KindHelmRepository:
+		spec.Type = fluxHelmRepositoryType
+	default:
+		return nil, fmt.Errorf("unsupported Flux source kind %q", kind)
+	}
+
+	return &FluxSource{
+		APIVersion: APIVersionFluxSource,
+		Kind:       kind,
+		Metadata: Metadata{
+			Name:      sourceName(opts.Path, secretName),
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}, nil
+}
+
+// ToYAML converts the FluxSource struct to a YAML string with the given indentation.
+func (f *FluxSource) ToYAML(indent int) (string, error) {
+	return toYAML(f, indent)
+}
+
+// ArgoCDRepository returns the Argo CD repository secret for the registry credentials of the secret
+func (s *Secret) ArgoCDRepository(opts OCIRepositoryOptions) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return NewArgoCDRepositorySecret(registry, auth.Username, auth.Password, s.Metadata.Name, s.Metadata.Namespace, opts).ToYAMLWith(pullsecret.EncodeOptions{Indent: opts.Indent})
+}
+
+// FluxSource returns the pull secret together with the Flux source of the given kind referencing it
+func (s *Secret) FluxSource(kind string, opts OCIRepositoryOptions) (string, error) {
+	registry, _, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	namespace := defaultNamespace(s.Metadata.Namespace, FluxNamespace)
+
+	// the secret has to live in the namespace of the source
+	secret := *s
+	secret.Metadata.Namespace = namespace
+
+	secretYAML, err := secret.ToYAMLWith(pullsecret.EncodeOptions{Indent: opts.Indent})
+	if err != nil {
+		return "", err
+	}
+
+	source, err := NewFluxSource(kind, registry, s.Metadata.Name, namespace, opts)
+	if err != nil {
+		return "", err
+	}
+
+	sourceYAML, err := source.ToYAML(opts.Indent)
+	if err != nil {
+		return "", err
+	}
+
+	return joinYAMLDocuments(secretYAML, sourceYAML), nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewArgoCDRepositorySecret(t *testing.T) {
+	secret := NewArgoCDRepositorySecret("https://ghcr.io/", "user", "token", "ghcr-charts", "", OCIRepositoryOptions{Path: "/org/charts/"})
+
+	assert.Equal(t, SecretTypeOpaque, secret.Type)
+	assert.Equal(t, ArgoCDNamespace, secret.Metadata.Namespace)
+	assert.Equal(t, ArgoCDSecretTypeRepo, secret.Metadata.Labels[ArgoCDSecretTypeLabel])
+	assert.Empty(t, secret.Data)
+	assert.Equal(t, "ghcr.io/org/charts", secret.StringData["url"])
+	assert.Equal(t, "helm", secret.StringData["type"])
+	assert.Equal(t, "true", secret.StringData["enableOCI"])
+	assert.Equal(t, "user", secret.StringData["username"])
+	assert.Equal(t, "token", secret.StringData["password"])
+
+	yamlStr, err := secret.ToYAML()
+	assert.NoError(t, err)
+	assert.Contains(t, yamlStr, "argocd.argoproj.io/secret-type: repository")
+	assert.Contains(t, yamlStr, "stringData:")
+	assert.NotContains(t, yamlStr, "\ndata:")
+}
+
+func TestNewFluxSource(t *testing.T) {
+	oci, err := NewFluxSource(KindOCIRepository, "ghcr.io", "mysecret", "flux-system", OCIRepositoryOptions{Path: "org/podinfo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "podinfo", oci.Metadata.Name)
+	assert.Equal(t, "oci://ghcr.io/org/podinfo", oci.Spec.URL)
+	assert.Equal(t, DefaultFluxInterval, oci.Spec.Interval)
+	assert.Equal(t, DefaultFluxTag, oci.Spec.Ref.Tag)
+	assert.Equal(t, "mysecret", oci.Spec.SecretRef.Name)
+	assert.Empty(t, oci.Spec.Type)
+
+	helm, err := NewFluxSource(KindHelmRepository, "ghcr.io", "mysecret", "apps", OCIRepositoryOptions{Interval: "1h"})
+	assert.NoError(t, err)
+	assert.Equal(t, "mysecret", helm.Metadata.Name)
+	assert.Equal(t, "oci", helm.Spec.Type)
+	assert.Equal(t, "1h", helm.Spec.Interval)
+	assert.Nil(t, helm.Spec.Ref)
+
+	_, err = NewFluxSource("GitRepository", "ghcr.io", "mysecret", "apps", OCIRepositoryOptions{})
+	assert.Error(t, err)
+}
+
+func TestSecretFluxSource(t *testing.T) {
+	secret, _ := NewImagePullSecret("ghcr.io", "user", "pass", "mysecret", "")
+
+	out, err := secret.FluxSource(KindOCIRepository, OCIRepositoryOptions{Path: "org/podinfo"})
+	assert.NoError(t, err)
+
+	docs := strings.Split(out, yamlDocumentSeparator)
+	assert.Len(t, docs, 2)
+
+	var pullSecret Secret
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[0]), &pullSecret))
+	assert.Equal(t, SecretTypeDockerConfigJSON, pullSecret.Type)
+	assert.Equal(t, FluxNamespace, pullSecret.Metadata.Namespace)
+
+	var source FluxSource
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[1]), &source))
+	assert.Equal(t, KindOCIRepository, source.Kind)
+	assert.Equal(t, FluxNamespace, source.Metadata.Namespace)
+	assert.Equal(t, "mysecret", source.Spec.SecretRef.Name)
+
+	// the generated secret itself is not changed
+	assert.Empty(t, secret.Metadata.Namespace)
+}
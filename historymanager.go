@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+const historyFileName = "registrymate-history.json"
+
+// historyTabTitles are the tab titles of the history lists
+var historyTabTitles = map[utils.HistoryKind]string{
+	utils.HistoryRegistries: "Registries",
+	utils.HistoryNames:      "Secret-Names",
+	utils.HistoryNamespaces: "Namespaces",
+}
+
+// openHistoryManager shows a window to search, pin and remove single history entries
+func (g *generator) openHistoryManager() {
+	if g.historyWindow != nil {
+		g.historyWindow.RequestFocus()
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow("History")
+	g.historyWindow = w
+	w.SetOnClosed(func() { g.historyWindow = nil })
+
+	var refreshers []func()
+	refresh := func() {
+		for _, r := range refreshers {
+			r()
+		}
+		g.updateEntries()
+	}
+
+	tabs := container.NewAppTabs()
+	for _, kind := range utils.HistoryKinds {
+		tab, refreshTab := g.historyTab(kind, refresh)
+		refreshers = append(refreshers, refreshTab)
+		tabs.Append(container.NewTabItem(historyTabTitles[kind], tab))
+	}
+
+	importBtn := widget.NewButtonWithIcon("Import", theme.FolderOpenIcon(), func() { g.importHistory(w, refresh) })
+	exportBtn := widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), func() { g.exportHistory(w) })
+	clearBtn := widget.NewButtonWithIcon("Clear all", theme.DeleteIcon(), func() {
+		dialog.ShowConfirm("Clear History", "Do you really want to delete the history?", func(confirmed bool) {
+			if confirmed {
+				g.appSettings.History.Clear()
+				refresh()
+				g.showToast("History Cleared")
+			}
+		}, w)
+	})
+
+	w.SetContent(container.NewBorder(nil, container.NewHBox(importBtn, exportBtn, clearBtn), nil, nil, tabs))
+	w.Resize(fyne.NewSize(520, 480))
+	w.Show()
+}
+
+// historyTab lists the entries of one history list with a search field, it returns the tab and its refresh function
+func (g *generator) historyTab(kind utils.HistoryKind, refreshAll func()) (fyne.CanvasObject, func()) {
+	history := g.appSettings.History
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search...")
+
+	entries := history.Search(kind, "")
+
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(widget.NewCheck("Pinned", nil), widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)),
+				widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			value := entries[id]
+			row := item.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(historyEntryText(history, kind, value))
+
+			actions := row.Objects[1].(*fyne.Container)
+			pinCheck := actions.Objects[0].(*widget.Check)
+			// reset the callback first, SetChecked would pin the previous entry of the reused row
+			pinCheck.OnChanged = nil
+			pinCheck.SetChecked(history.IsPinned(kind, value))
+			pinCheck.OnChanged = func(pinned bool) {
+				history.Pin(kind, value, pinned)
+				refreshAll()
+			}
+
+			actions.Objects[1].(*widget.Button).OnTapped = func() {
+				history.Remove(kind, value)
+				refreshAll()
+			}
+		},
+	)
+
+	refresh := func() {
+		entries = history.Search(kind, searchEntry.Text)
+		list.Refresh()
+	}
+	searchEntry.OnChanged = func(string) { refresh() }
+
+	return container.NewBorder(searchEntry, nil, nil, nil, list), refresh
+}
+
+// historyEntryText returns the value with its use count and last use
+func historyEntryText(history *utils.AppHistory, kind utils.HistoryKind, value string) string {
+	entry, ok := history.Entry(kind, value)
+	if !ok {
+		return value
+	}
+	if entry.LastUsed.IsZero() {
+		return fmt.Sprintf("%s  (%dx)", value, entry.Count)
+	}
+	return fmt.Sprintf("%s  (%dx, %s)", value, entry.Count, entry.LastUsed.Local().Format("2006-01-02 15:04"))
+}
+
+// exportHistory saves all history entries as JSON
+func (g *generator) exportHistory(parent fyne.Window) {
+	content, err := g.appSettings.History.ExportJSON()
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(
+		func(uriWriter fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+			if uriWriter == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriWriter.Close(); err != nil {
+					log.Printf("File-Save - failed to close uriWriter: %v", err)
+				}
+			}()
+
+			originalPath := uriWriter.URI().Path()
+
+			// delete the empty file created by the dialog
+			_ = os.Remove(originalPath)
+
+			if err := utils.WriteFile(utils.EnsureExt(originalPath, ".json"), content); err != nil {
+				dialog.ShowError(err, parent)
+			} else {
+				g.showToast("History exported")
+			}
+		},
+		parent,
+	)
+
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	saveDialog.SetFileName(historyFileName)
+	saveDialog.SetTitleText("Export History")
+	saveDialog.Resize(fyne.NewSize(600.0, 400.0))
+	saveDialog.Show()
+}
+
+// importHistory merges an exported JSON file into the history
+func (g *generator) importHistory(parent fyne.Window, onImported func()) {
+	openDialog := dialog.NewFileOpen(
+		func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriReader.Close(); err != nil {
+					log.Printf("History-Import - failed to close uriReader: %v", err)
+				}
+			}()
+
+			content, err := io.ReadAll(uriReader)
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+
+			added, err := g.appSettings.History.ImportJSON(content)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", uriReader.URI().Name(), err), parent)
+				return
+			}
+
+			onImported()
+			g.showToast(fmt.Sprintf("%d history entries imported", added))
+		},
+		parent,
+	)
+
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	openDialog.SetTitleText("Import History")
+	openDialog.Resize(fyne.NewSize(600.0, 400.0))
+	openDialog.Show()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shell selects the quoting rules of the generated command line
+type Shell int
+
+const (
+	ShellPOSIX Shell = iota
+	ShellPowerShell
+)
+
+// characters that never need quoting, PowerShell additionally treats '@' and ',' as special
+const (
+	posixSafeChars      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=@%+,"
+	powerShellSafeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=%+"
+)
+
+// QuotePOSIX quotes s for POSIX shells, single quotes inside s are written as
+//
+//	'\''
+func QuotePOSIX(s string) string {
+	if s != "" && strings.Trim(s, posixSafeChars) == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuotePowerShell quotes s as a verbatim PowerShell string. Single quotes, including the
+// typographic variants PowerShell accepts as single quotes, are doubled.
+func QuotePowerShell(s string) string {
+	if s != "" && strings.Trim(s, powerShellSafeChars) == "" {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '‘', '’', '‚', '‛':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// KubectlCommand returns the kubectl command line which creates the same secret as NewImagePullSecret
+func KubectlCommand(registry, user, pass, name, namespace string, shell Shell) string {
+	quote := QuotePOSIX
+	continuation := " \\"
+	if shell == ShellPowerShell {
+		quote = QuotePowerShell
+		continuation = " `"
+	}
+
+	args := []string{
+		"kubectl create secret docker-registry " + quote(name),
+		"--docker-server=" + quote(registry),
+		"--docker-username=" + quote(user),
+		"--docker-password=" + quote(pass),
+	}
+	if namespace != "" {
+		args = append(args, "--namespace="+quote(namespace))
+	}
+	args = append(args, "--dry-run=client -o yaml")
+
+	var b strings.Builder
+
+	// Windows PowerShell 5.1 drops embedded double quotes when calling native programs
+	if shell == ShellPowerShell && strings.ContainsRune(user+pass, '"') {
+		b.WriteString("# Requires PowerShell 7.3 or later, older versions strip the double quotes of the arguments\n")
+	}
+
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(arg)
+		if i < len(args)-1 {
+			b.WriteString(continuation)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// KubectlCommand returns the kubectl command line for the registry credentials of the secret
+func (s *Secret) KubectlCommand(shell Shell) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return KubectlCommand(registry, auth.Username, auth.Password, s.Metadata.Name, s.Metadata.Namespace, shell), nil
+}
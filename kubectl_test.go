@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var quotingSamples = []string{
+	"docker.io",
+	"robot$ci",
+	"it's",
+	`pa"ss`,
+	"`whoami`",
+	"$(id)",
+	"a b\tc",
+	"semi;colon&amp|pipe",
+	"new\nline",
+	"back\\slash",
+	"!bang*glob?[x]",
+	"~tilde",
+	"",
+}
+
+func TestQuotePOSIX(t *testing.T) {
+	assert.Equal(t, "docker.io", QuotePOSIX("docker.io"))
+	assert.Equal(t, "'robot$ci'", QuotePOSIX("robot$ci"))
+	assert.Equal(t, `'it'\''s'`, QuotePOSIX("it's"))
+	assert.Equal(t, "''", QuotePOSIX(""))
+}
+
+func TestQuotePOSIX_Shell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell available")
+	}
+
+	for _, sample := range quotingSamples {
+		out, err := exec.Command(sh, "-c", "printf '%s' "+QuotePOSIX(sample)).Output()
+		assert.NoError(t, err)
+		assert.Equal(t, sample, string(out), "quoted: %s", QuotePOSIX(sample))
+	}
+}
+
+func TestQuotePowerShell(t *testing.T) {
+	assert.Equal(t, "docker.io", QuotePowerShell("docker.io"))
+	assert.Equal(t, "'robot$ci'", QuotePowerShell("robot$ci"))
+	assert.Equal(t, "'it''s'", QuotePowerShell("it's"))
+	assert.Equal(t, "'it’’s'", QuotePowerShell("it’s"))
+	assert.Equal(t, "'user@example.com'", QuotePowerShell("user@example.com"))
+	assert.Equal(t, "''", QuotePowerShell(""))
+}
+
+func TestKubectlCommand(t *testing.T) {
+	cmd := KubectlCommand("registry.example.com", "robot$ci", "it's", "mysecret", "apps", ShellPOSIX)
+	lines := strings.Split(strings.TrimSpace(cmd), "\n")
+
+	assert.Equal(t, []string{
+		`kubectl create secret docker-registry mysecret \`,
+		`  --docker-server=registry.example.com \`,
+		`  --docker-username='robot$ci' \`,
+		`  --docker-password='it'\''s' \`,
+		`  --namespace=apps \`,
+		`  --dry-run=client -o yaml`,
+	}, lines)
+
+	cmd = KubectlCommand("registry.example.com", "robot$ci", "it's", "mysecret", "", ShellPowerShell)
+	lines = strings.Split(strings.TrimSpace(cmd), "\n")
+
+	assert.Equal(t, []string{
+		"kubectl create secret docker-registry mysecret `",
+		"  --docker-server=registry.example.com `",
+		"  --docker-username='robot$ci' `",
+		"  --docker-password='it''s' `",
+		"  --dry-run=client -o yaml",
+	}, lines)
+}
+
+func TestKubectlCommand_PowerShellDoubleQuotes(t *testing.T) {
+	cmd := KubectlCommand("docker.io", "user", `pa"ss`, "mysecret", "", ShellPowerShell)
+	assert.True(t, strings.HasPrefix(cmd, "# Requires PowerShell 7.3"))
+	assert.Contains(t, cmd, `--docker-password='pa"ss'`)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+// LintFileResult is the lint result of one manifest file
+type LintFileResult struct {
+	Path   string
+	Result *pullsecret.LintResult
+	// the file could not be read or parsed
+	Err error
+}
+
+// ManifestFiles returns the YAML files of the given files and directories, directories are searched recursively
+func ManifestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() && file != path && strings.HasPrefix(entry.Name(), ".") {
+				// e.g. .git
+				return filepath.SkipDir
+			}
+			if !entry.IsDir() && isYAMLFile(file) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// LintFiles lints every file, a file which can not be read does not stop the others
+func LintFiles(files []string) []LintFileResult {
+	results := make([]LintFileResult, 0, len(files))
+	for _, file := range files {
+		result := LintFileResult{Path: file}
+		content, err := os.ReadFile(file)
+		if err == nil {
+			result.Result, err = pullsecret.Lint(content)
+		}
+		result.Err = err
+		results = append(results, result)
+	}
+	return results
+}
+
+// Fixable reports whether the file has findings which can be corrected
+func (r LintFileResult) Fixable() bool {
+	return r.Err == nil && r.Result.Changed()
+}
+
+// WriteFixed writes the corrected manifest to the path, usually the linted file itself. A file
+// without fixes is copied unchanged.
+func (r LintFileResult) WriteFixed(path string) error {
+	if !r.Result.Changed() {
+		if path == r.Path {
+			return nil
+		}
+		content, err := os.ReadFile(r.Path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, cliFileMode)
+	}
+
+	fixed, err := r.Result.Fixed()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, fixed, cliFileMode)
+}
+
+// LintReport returns the findings of all files and a summary, files without secrets are not listed
+func LintReport(results []LintFileResult) string {
+	var b strings.Builder
+	secrets, errs, warnings, fixable := 0, 0, 0, 0
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(&b, "%s: ERROR %v\n", r.Path, r.Err)
+			errs++
+			continue
+		}
+
+		secrets += r.Result.Secrets
+		errs += r.Result.Errors()
+		warnings += r.Result.Warnings()
+		for _, f := range r.Result.Findings {
+			fmt.Fprintf(&b, "%s: %s\n", r.Path, f)
+			if f.Fixable {
+				fixable++
+			}
+		}
+	}
+	fmt.Fprintf(&b, "%d secrets in %d files, %d errors, %d warnings, %d fixable\n", secrets, len(results), errs, warnings, fixable)
+	return b.String()
+}
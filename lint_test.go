@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "apps", ".git"), 0o755))
+	for _, name := range []string{"a.yaml", "apps/b.yml", "apps/readme.md", "apps/.git/c.yaml"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	files, err := ManifestFiles([]string{dir, filepath.Join(dir, "apps", "readme.md")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.yaml"),
+		filepath.Join(dir, "apps", "b.yml"),
+		filepath.Join(dir, "apps", "readme.md"),
+	}, files)
+
+	_, err = ManifestFiles([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
+
+func TestRunCLI_Lint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := base64.StdEncoding.EncodeToString([]byte(`{"auths":{"ghcr.io":{"username":"ci","password":"token","auth":"wrong"}}}`))
+	broken := filepath.Join(dir, "broken.yaml")
+	assert.NoError(t, os.WriteFile(broken, []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: ghcr-pull\ntype: Opaque\ndata:\n  .dockerconfigjson: "+cfg+"\n"), 0o600))
+	secret, _ := NewImagePullSecret("ghcr.io", "ci", "token", "valid", "")
+	valid, _ := secret.ToYAML()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "valid.yaml"), []byte(valid), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := runCLI([]string{"lint", dir}, &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stdout.String(), broken+": document 1 (ghcr-pull): error type: ")
+	assert.Contains(t, stdout.String(), "2 secrets in 2 files, 2 errors, 0 warnings, 2 fixable\n")
+
+	fixedFile := filepath.Join(dir, "fixed.out")
+	assert.Equal(t, exitOK, runCLI([]string{"lint", "-o", fixedFile, broken}, &stdout, &stderr))
+	content, err := os.ReadFile(fixedFile)
+	assert.NoError(t, err)
+	fixed, err := pullsecret.Parse(content)
+	assert.NoError(t, err)
+	assert.NoError(t, fixed.Validate())
+
+	assert.Equal(t, exitOK, runCLI([]string{"lint", "-fix", dir}, &stdout, &stderr))
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLI([]string{"lint", dir}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "2 secrets in 2 files, 0 errors, 0 warnings, 0 fixable\n")
+
+	assert.Equal(t, exitUsage, runCLI([]string{"lint"}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLI([]string{"lint", "-o", fixedFile, dir}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLI([]string{"lint", "-fix", "-o", fixedFile, broken}, &stdout, &stderr))
+}
+
+func TestRunCLI_LintOutputWithoutFindings(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"empty.yaml": "", "null.yaml": "---\n~\n---\n"} {
+		input := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(input, []byte(content), 0o600))
+
+		var stdout, stderr bytes.Buffer
+		out := filepath.Join(dir, name+".out")
+		assert.Equal(t, exitOK, runCLI([]string{"lint", "-o", out, input}, &stdout, &stderr), stderr.String())
+		written, err := os.ReadFile(out)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(written), "the input is written unchanged")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+// lintRow is one line of the lint window, a file or one of its findings
+type lintRow struct {
+	icon fyne.Resource
+	text string
+}
+
+// openLint shows a window which checks secret files for typical faults and writes the fixes
+func (g *generator) openLint() {
+	if g.lintWindow != nil {
+		g.lintWindow.RequestFocus()
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow("Lint Pull Secrets")
+	g.lintWindow = w
+	w.SetOnClosed(func() { g.lintWindow = nil })
+
+	var paths []string
+	var results []LintFileResult
+	var rows []lintRow
+
+	list := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.FileIcon()), nil, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			border := item.(*fyne.Container)
+			border.Objects[0].(*widget.Label).SetText(rows[id].text)
+			border.Objects[1].(*widget.Icon).SetResource(rows[id].icon)
+		},
+	)
+
+	summary := widget.NewLabel("Open a secret file or a folder, documents other than Secrets are skipped")
+	summary.Wrapping = fyne.TextWrapWord
+
+	fixBtn := widget.NewButtonWithIcon("Write Fixes", theme.DocumentSaveIcon(), nil)
+	fixBtn.Disable() // enabled when a file has fixable findings
+
+	lint := func(newPaths []string) {
+		files, err := ManifestFiles(newPaths)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		paths = newPaths
+		results = LintFiles(files)
+		rows = lintRows(results)
+		list.Refresh()
+
+		report := strings.Split(strings.TrimSuffix(LintReport(results), "\n"), "\n")
+		summary.SetText(report[len(report)-1])
+
+		fixBtn.Disable()
+		for _, r := range results {
+			if r.Fixable() {
+				fixBtn.Enable()
+			}
+		}
+	}
+
+	fixBtn.OnTapped = func() { g.writeLintFixes(w, results, func() { lint(paths) }) }
+
+	openFileBtn := widget.NewButtonWithIcon("Open File", theme.FileIcon(), func() {
+		openDialog := dialog.NewFileOpen(func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+			// only the path is needed, the files are read by the linter
+			_ = uriReader.Close()
+			lint([]string{uriReader.URI().Path()})
+		}, w)
+		openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+		openDialog.Resize(fyne.NewSize(600.0, 400.0))
+		openDialog.Show()
+	})
+
+	openFolderBtn := widget.NewButtonWithIcon("Open Folder", theme.FolderOpenIcon(), func() {
+		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uri == nil {
+				// cancelled
+				return
+			}
+			lint([]string{uri.Path()})
+		}, w)
+		folderDialog.Resize(fyne.NewSize(600.0, 400.0))
+		folderDialog.Show()
+	})
+
+	w.SetContent(container.NewBorder(
+		container.NewHBox(openFileBtn, openFolderBtn),
+		container.NewBorder(nil, nil, nil, fixBtn, summary),
+		nil, nil,
+		list,
+	))
+	w.Resize(fyne.NewSize(820, 520))
+	w.Show()
+}
+
+// writeLintFixes rewrites the files with fixable findings after a confirmation
+func (g *generator) writeLintFixes(parent fyne.Window, results []LintFileResult, onWritten func()) {
+	var fixable []LintFileResult
+	for _, r := range results {
+		if r.Fixable() {
+			fixable = append(fixable, r)
+		}
+	}
+
+	message := fmt.Sprintf("Rewrite %d files with the fixable findings corrected?\n"+
+		"Secrets whose type changes must be deleted in the cluster before they are applied again.", len(fixable))
+	dialog.ShowConfirm("Write Fixes", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		written := 0
+		var writeErr error
+		for _, r := range fixable {
+			if writeErr = r.WriteFixed(r.Path); writeErr != nil {
+				break
+			}
+			written++
+		}
+		// the files written so far are linted again in any case
+		onWritten()
+		if writeErr != nil {
+			dialog.ShowError(fmt.Errorf("fixed %d of %d files: %w", written, len(fixable), writeErr), parent)
+			return
+		}
+		g.showToast(fmt.Sprintf("Fixed %d files", written))
+	}, parent)
+}
+
+// lintRows lists every file with secrets or errors followed by its findings
+func lintRows(results []LintFileResult) []lintRow {
+	var rows []lintRow
+	for _, r := range results {
+		if r.Err != nil {
+			rows = append(rows, lintRow{theme.ErrorIcon(), fmt.Sprintf("%s: %v", r.Path, r.Err)})
+			continue
+		}
+		if r.Result.Secrets == 0 {
+			continue
+		}
+
+		status := "no findings"
+		if n := len(r.Result.Findings); n > 0 {
+			status = fmt.Sprintf("%d findings", n)
+		}
+		rows = append(rows, lintRow{theme.FileIcon(), fmt.Sprintf("%s: %d secrets, %s", r.Path, r.Result.Secrets, status)})
+
+		for _, f := range r.Result.Findings {
+			icon := theme.WarningIcon()
+			if f.Severity == pullsecret.SeverityError {
+				icon = theme.ErrorIcon()
+			}
+			rows = append(rows, lintRow{icon, "    " + f.String()})
+		}
+	}
+	return rows
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"os"
+
 	"fyne.io/fyne/v2/app"
 	"github.com/javaLux/registrymate/utils"
 )
 
 func main() {
+	// a subcommand runs the command line interface instead of the GUI, other arguments like the
+	// -psn_ argument of macOS or a file passed by a desktop launcher still start the GUI
+	if isCLICommand(os.Args[1:]) {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	app := app.New()
 
 	// load app settings from config
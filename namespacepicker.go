@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+// pickNamespaces lets the user select several namespaces from the history and the kubeconfig contexts
+func (g *generator) pickNamespaces() {
+	selected := utils.ParseNamespaces(g.nameSpaceEntry.Text)
+
+	options := g.appSettings.History.SortedNamespaces()
+	for _, namespace := range append(utils.KubeconfigNamespaces(), selected...) {
+		if !slices.Contains(options, namespace) {
+			options = append(options, namespace)
+		}
+	}
+
+	if len(options) == 0 {
+		dialog.ShowInformation("Select Namespaces", "There are no namespaces in the history or the kubeconfig yet.", g.window)
+		return
+	}
+
+	checkGroup := widget.NewCheckGroup(options, nil)
+	checkGroup.SetSelected(selected)
+
+	pickDialog := dialog.NewCustomConfirm("Select Namespaces", "Apply", "Cancel",
+		container.NewVScroll(checkGroup),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			// keep the order of the list
+			var namespaces []string
+			for _, namespace := range options {
+				if slices.Contains(checkGroup.Selected, namespace) {
+					namespaces = append(namespaces, namespace)
+				}
+			}
+			g.nameSpaceEntry.SetText(utils.JoinNamespaces(namespaces))
+		},
+		g.window,
+	)
+	pickDialog.Resize(fyne.NewSize(400, 420))
+	pickDialog.Show()
+}
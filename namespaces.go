@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+const KindNamespace = "Namespace"
+
+type Namespace struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+}
+
+// NewNamespace creates the manifest of a namespace
+func NewNamespace(name string) *Namespace {
+	return &Namespace{
+		APIVersion: APIVersionV1,
+		Kind:       KindNamespace,
+		Metadata:   Metadata{Name: name},
+	}
+}
+
+// ToYAML converts the Namespace struct to a YAML string with the given indentation.
+func (n *Namespace) ToYAML(indent int) (string, error) {
+	return toYAML(n, indent)
+}
+
+// WithNamespace returns a copy of the secret in another namespace
+func (s *Secret) WithNamespace(namespace string) *Secret {
+	secret := *s
+	secret.Metadata.Namespace = namespace
+	return &secret
+}
+
+// namespaceNames renders the name template for every namespace, nil if there is only one namespace or the
+// template does not depend on the namespace. The secret itself is named for the first namespace only.
+func namespaceNames(nameTemplate, registry, user string, namespaces []string) (map[string]string, error) {
+	if nameTemplate == "" || len(namespaces) < 2 {
+		return nil, nil
+	}
+
+	// the same date and random part for all names, they differ only by the namespace
+	data := pullsecret.NewNameData(registry, user, namespaces[0], time.Now())
+	names := make(map[string]string, len(namespaces))
+	distinct := false
+	for _, namespace := range namespaces {
+		data.Namespace = namespace
+		name, err := pullsecret.RenderName(nameTemplate, data)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+		distinct = distinct || (len(names) > 0 && name != names[namespaces[0]])
+		names[namespace] = name
+	}
+
+	if !distinct {
+		return nil, nil
+	}
+	return names, nil
+}
+
+// FanOut renders a copy of the secret for every namespace and joins the results to a multi-document YAML,
+// optionally each preceded by the manifest of its namespace written with the given indentation.
+// The copies are renamed to the names of their namespace, e.g. rendered by namespaceNames.
+func (s *Secret) FanOut(namespaces []string, names map[string]string, withNamespaces bool, indent int, render func(*Secret) (string, error)) (string, error) {
+	if len(namespaces) == 0 {
+		return render(s)
+	}
+
+	docs := make([]string, 0, 2*len(namespaces))
+	for _, namespace := range namespaces {
+		if withNamespaces {
+			namespaceYAML, err := NewNamespace(namespace).ToYAML(indent)
+			if err != nil {
+				return "", err
+			}
+			docs = append(docs, namespaceYAML)
+		}
+
+		secret := s.WithNamespace(namespace)
+		if name, ok := names[namespace]; ok {
+			secret.Metadata.Name = name
+		}
+		doc, err := render(secret)
+		if err != nil {
+			return "", fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return joinYAMLDocuments(docs...), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFanOut(t *testing.T) {
+	secret, _ := NewImagePullSecret("ghcr.io", "user", "pass", "ghcr-pull", "team-a")
+
+	out, err := secret.FanOut([]string{"team-a", "team-b"}, nil, false, 0, (*Secret).ToYAML)
+	assert.NoError(t, err)
+
+	docs := strings.Split(out, yamlDocumentSeparator)
+	assert.Len(t, docs, 2)
+	for i, namespace := range []string{"team-a", "team-b"} {
+		var s Secret
+		assert.NoError(t, yaml.Unmarshal([]byte(docs[i]), &s))
+		assert.Equal(t, "ghcr-pull", s.Metadata.Name)
+		assert.Equal(t, namespace, s.Metadata.Namespace)
+		assert.Equal(t, secret.Data, s.Data)
+	}
+
+	// the original secret is not modified
+	assert.Equal(t, "team-a", secret.Metadata.Namespace)
+}
+
+func TestFanOut_NamespaceManifests(t *testing.T) {
+	secret, _ := NewImagePullSecret("ghcr.io", "user", "pass", "ghcr-pull", "")
+
+	out, err := secret.FanOut([]string{"team-a", "team-b"}, nil, true, 0, (*Secret).ToYAML)
+	assert.NoError(t, err)
+
+	docs := strings.Split(out, yamlDocumentSeparator)
+	assert.Len(t, docs, 4)
+
+	var namespace Namespace
+	assert.NoError(t, yaml.Unmarshal([]byte(docs[2]), &namespace))
+	assert.Equal(t, KindNamespace, namespace.Kind)
+	assert.Equal(t, "team-b", namespace.Metadata.Name)
+
+	// without namespaces the secret is rendered unchanged
+	out, err = secret.FanOut(nil, nil, true, 0, (*Secret).ToYAML)
+	assert.NoError(t, err)
+	assert.NotContains(t, out, KindNamespace)
+}
+
+func TestFanOut_Indent(t *testing.T) {
+	secret, _ := NewImagePullSecret("ghcr.io", "user", "pass", "ghcr-pull", "")
+	render := func(s *Secret) (string, error) {
+		return s.ToYAMLWith(pullsecret.EncodeOptions{Indent: 4})
+	}
+
+	out, err := secret.FanOut([]string{"team-a"}, nil, true, 4, render)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "kind: Namespace\nmetadata:\n    name: team-a\n")
+	assert.Contains(t, out, "metadata:\n    name: ghcr-pull\n    namespace: team-a\n")
+	assert.NotContains(t, out, "\n  name:")
+}
+
+func TestFanOut_NamespaceNames(t *testing.T) {
+	namespaces := []string{"team-a", "team-b"}
+	names, err := namespaceNames("{{.RegistryHost}}-{{.Namespace}}", "ghcr.io", "user", namespaces)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team-a": "ghcr.io-team-a", "team-b": "ghcr.io-team-b"}, names)
+
+	secret, _ := NewImagePullSecret("ghcr.io", "user", "pass", names["team-a"], "team-a")
+	out, err := secret.FanOut(namespaces, names, false, 0, (*Secret).ToYAML)
+	assert.NoError(t, err)
+
+	docs := strings.Split(out, yamlDocumentSeparator)
+	assert.Len(t, docs, 2)
+	for i, namespace := range namespaces {
+		var s Secret
+		assert.NoError(t, yaml.Unmarshal([]byte(docs[i]), &s))
+		assert.Equal(t, "ghcr.io-"+namespace, s.Metadata.Name)
+		assert.Equal(t, namespace, s.Metadata.Namespace)
+	}
+
+	// templates without namespace and single namespaces keep the one name
+	names, err = namespaceNames("{{.RegistryHost}}-pull", "ghcr.io", "user", namespaces)
+	assert.NoError(t, err)
+	assert.Nil(t, names)
+	names, err = namespaceNames("{{.Namespace}}", "ghcr.io", "user", namespaces[:1])
+	assert.NoError(t, err)
+	assert.Nil(t, names)
+
+	_, err = namespaceNames("{{.Unknown}}", "ghcr.io", "user", namespaces)
+	assert.Error(t, err)
+}
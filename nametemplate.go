@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/javaLux/registrymate/utils"
+)
+
+// sampleNameData are the values used to check a name template before it is saved
+var sampleNameData = pullsecret.NameData{
+	RegistryHost: "registry.example.com",
+	Namespace:    "default",
+	Username:     "user",
+	Date:         "20060102",
+	Random:       "a1b2",
+}
+
+// editNameTemplate lets the user edit the template of generated secret names with a live preview
+func (g *generator) editNameTemplate() {
+	templateEntry := widget.NewEntry()
+	templateEntry.SetPlaceHolder("e.g. {{.RegistryHost}}-pull (empty for random names)")
+	templateEntry.SetText(g.appSettings.NameTemplate)
+
+	preview := widget.NewLabel("")
+	preview.TextStyle.Monospace = true
+	preview.Wrapping = fyne.TextWrapWord
+
+	// the preview uses the current input, the random suffix changes on every key stroke
+	updatePreview := func(tmpl string) {
+		tmpl = strings.TrimSpace(tmpl)
+		if tmpl == "" {
+			preview.SetText("Preview: random name, e.g. " + pullsecret.GenerateName())
+			return
+		}
+
+		namespace := ""
+		if namespaces := utils.ParseNamespaces(g.nameSpaceEntry.Text); len(namespaces) > 0 {
+			namespace = namespaces[0]
+		}
+		data := pullsecret.NewNameData(strings.TrimSpace(g.regEntry.Text), strings.TrimSpace(g.userEntry.Text), namespace, time.Now())
+
+		name, err := pullsecret.RenderName(tmpl, data)
+		if err != nil {
+			preview.SetText("Invalid template: " + err.Error())
+			return
+		}
+		preview.SetText("Preview: " + name)
+	}
+	templateEntry.OnChanged = updatePreview
+	updatePreview(templateEntry.Text)
+
+	placeholders := widget.NewLabel(strings.Join(pullsecret.NameTemplatePlaceholders, "\n"))
+	placeholders.TextStyle.Monospace = true
+
+	templateDialog := dialog.NewCustomConfirm("Secret Name Template", "Save", "Cancel",
+		container.NewVBox(
+			widget.NewLabel("Used if the secret name is empty:"),
+			templateEntry,
+			preview,
+			widget.NewSeparator(),
+			placeholders,
+		),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			// check the template with sample values, unknown placeholders fail independent of the input
+			if tmpl := strings.TrimSpace(templateEntry.Text); tmpl != "" {
+				if _, err := pullsecret.RenderName(tmpl, sampleNameData); err != nil {
+					dialog.ShowError(err, g.window)
+					return
+				}
+			}
+			g.appSettings.NameTemplate = strings.TrimSpace(templateEntry.Text)
+		},
+		g.window,
+	)
+	templateDialog.Resize(fyne.NewSize(550, 350))
+	templateDialog.Show()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	APIVersionKind = "kind.x-k8s.io/v1alpha4"
+	KindCluster    = "Cluster"
+	// host containerd pulls Docker Hub images from
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+type K3sRegistries struct {
+	Configs map[string]K3sRegistryConfig `yaml:"configs"`
+}
+
+type K3sRegistryConfig struct {
+	Auth K3sRegistryAuth `yaml:"auth"`
+}
+
+type K3sRegistryAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type KindClusterConfig struct {
+	Kind                    string   `yaml:"kind"`
+	APIVersion              string   `yaml:"apiVersion"`
+	ContainerdConfigPatches []string `yaml:"containerdConfigPatches"`
+}
+
+// NodeRegistryHost returns the registry host as used in the containerd registry configuration,
+// the scheme and path are removed and Docker Hub is mapped to its pull endpoint
+func NodeRegistryHost(registry string) string {
+	host := registry
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+
+	switch host {
+	case "docker.io", "index.docker.io":
+		return dockerHubRegistryHost
+	}
+
+	return host
+}
+
+// quoteTOML returns s as TOML basic string
+func quoteTOML(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ContainerdAuthConfig returns the registry auth block of the containerd CRI plugin for config.toml
+func ContainerdAuthConfig(registry, user, pass string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[plugins.\"io.containerd.grpc.v1.cri\".registry.configs.%s.auth]\n", quoteTOML(NodeRegistryHost(registry)))
+	fmt.Fprintf(&b, "  username = %s\n", quoteTOML(user))
+	fmt.Fprintf(&b, "  password = %s\n", quoteTOML(pass))
+	return b.String()
+}
+
+// K3sRegistriesConfig returns the configs entry of the K3s /etc/rancher/k3s/registries.yaml
+func K3sRegistriesConfig(registry, user, pass string, indent int) (string, error) {
+	registries := K3sRegistries{
+		Configs: map[string]K3sRegistryConfig{
+			NodeRegistryHost(registry): {
+				Auth: K3sRegistryAuth{
+					Username: user,
+					Password: pass,
+				},
+			},
+		},
+	}
+
+	return toYAML(registries, indent)
+}
+
+// KindConfig returns a kind cluster configuration which patches the containerd registry auth into all nodes
+func KindConfig(registry, user, pass string, indent int) (string, error) {
+	cluster := KindClusterConfig{
+		Kind:       KindCluster,
+		APIVersion: APIVersionKind,
+		ContainerdConfigPatches: []string{
+			ContainerdAuthConfig(registry, user, pass),
+		},
+	}
+
+	return toYAML(cluster, indent)
+}
+
+// ContainerdAuthConfig returns the containerd registry auth block for the registry credentials of the secret
+func (s *Secret) ContainerdAuthConfig() (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return ContainerdAuthConfig(registry, auth.Username, auth.Password), nil
+}
+
+// K3sRegistriesConfig returns the K3s registries.yaml for the registry credentials of the secret
+func (s *Secret) K3sRegistriesConfig(indent int) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return K3sRegistriesConfig(registry, auth.Username, auth.Password, indent)
+}
+
+// KindConfig returns the kind cluster configuration for the registry credentials of the secret
+func (s *Secret) KindConfig(indent int) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return KindConfig(registry, auth.Username, auth.Password, indent)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type containerdConfig struct {
+	Plugins map[string]struct {
+		Registry struct {
+			Configs map[string]struct {
+				Auth struct {
+					Username string `toml:"username"`
+					Password string `toml:"password"`
+				} `toml:"auth"`
+			} `toml:"configs"`
+		} `toml:"registry"`
+	} `toml:"plugins"`
+}
+
+func TestNodeRegistryHost(t *testing.T) {
+	assert.Equal(t, "registry.gitlab.com", NodeRegistryHost("registry.gitlab.com"))
+	assert.Equal(t, "registry.example.com:5000", NodeRegistryHost("https://registry.example.com:5000/v2/"))
+	assert.Equal(t, "registry-1.docker.io", NodeRegistryHost("docker.io"))
+	assert.Equal(t, "registry-1.docker.io", NodeRegistryHost("https://index.docker.io/v1/"))
+}
+
+func TestContainerdAuthConfig(t *testing.T) {
+	out := ContainerdAuthConfig("https://registry.example.com", `robot$ci`, "pa\"ss\\\n")
+
+	var cfg containerdConfig
+	_, err := toml.Decode(out, &cfg)
+	assert.NoError(t, err, out)
+
+	auth := cfg.Plugins["io.containerd.grpc.v1.cri"].Registry.Configs["registry.example.com"].Auth
+	assert.Equal(t, `robot$ci`, auth.Username)
+	assert.Equal(t, "pa\"ss\\\n", auth.Password)
+}
+
+func TestK3sRegistriesConfig(t *testing.T) {
+	out, err := K3sRegistriesConfig("docker.io", "user", "pass", 0)
+	assert.NoError(t, err)
+
+	var registries K3sRegistries
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &registries))
+	assert.Equal(t, K3sRegistryAuth{Username: "user", Password: "pass"}, registries.Configs["registry-1.docker.io"].Auth)
+}
+
+func TestKindConfig(t *testing.T) {
+	out, err := KindConfig("ghcr.io", "user", "pass", 0)
+	assert.NoError(t, err)
+
+	var cluster KindClusterConfig
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &cluster))
+	assert.Equal(t, KindCluster, cluster.Kind)
+	assert.Equal(t, APIVersionKind, cluster.APIVersion)
+	assert.Len(t, cluster.ContainerdConfigPatches, 1)
+
+	var cfg containerdConfig
+	_, err = toml.Decode(cluster.ContainerdConfigPatches[0], &cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", cfg.Plugins["io.containerd.grpc.v1.cri"].Registry.Configs["ghcr.io"].Auth.Username)
+}
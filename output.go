@@ -0,0 +1,598 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+// outputFormat describes one way of rendering the generated secret
+type outputFormat struct {
+	// name shown in the format selector
+	name string
+	// default file name for the save dialog
+	fileName string
+	// file extensions accepted by the save dialog, the first one is the default
+	extensions []string
+	// renders the given secret, which is the current secret or one of its namespace copies
+	render func(g *generator, s *Secret) (string, error)
+	// optional dialog to configure format specific settings, calls onSaved after changes
+	options func(g *generator, onSaved func())
+	// whether the docker config JSON can be shown decoded
+	decodable bool
+	// optional check of the rendered output, returns a warning to show or an empty string
+	check func(output string) string
+	// whether the output is rendered once per namespace and joined to a multi-document YAML
+	fanOut bool
+}
+
+// keys of the format options stored in the app settings
+const (
+	optSOPSRecipients       = "sops.recipients"
+	optESOStoreName         = "eso.storeName"
+	optESOStoreKind         = "eso.storeKind"
+	optESOUsernameKey       = "eso.usernameKey"
+	optESOUsernameProperty  = "eso.usernameProperty"
+	optESOPasswordKey       = "eso.passwordKey"
+	optESOPasswordProperty  = "eso.passwordProperty"
+	optESORefreshInterval   = "eso.refreshInterval"
+	optTerraformPassword    = "terraform.passwordVariable"
+	optAnsiblePassword      = "ansible.passwordVariable"
+	optOCIRepositoryPath    = "oci.repositoryPath"
+	optFluxInterval         = "flux.interval"
+	optFluxTag              = "flux.tag"
+	optBuildKey             = "build.key"
+	optBuildMountPath       = "build.mountPath"
+	optBuildSnippet         = "build.snippet"
+	optECRProfile           = "ecr.profile"
+	optECRRegion            = "ecr.region"
+	optECREndpoint          = "ecr.endpoint"
+	optECRSchedule          = "ecr.schedule"
+	optECRCredentialsSecret = "ecr.credentialsSecret"
+	optNamespaceManifests   = "namespaces.manifests"
+)
+
+var outputFormats = []outputFormat{
+	{
+		name:       "Secret (YAML)",
+		fileName:   "image-pull-secret.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render:     (*generator).renderSecretYAML,
+		decodable:  true,
+		fanOut:     true,
+	},
+	{
+		name:       "SOPS (age)",
+		fileName:   "image-pull-secret.sops.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render:     (*generator).renderSOPS,
+		options:    (*generator).sopsOptions,
+	},
+	{
+		name:       "ExternalSecret (ESO)",
+		fileName:   "external-secret.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render:     (*generator).renderExternalSecret,
+		options:    (*generator).externalSecretOptions,
+		fanOut:     true,
+	},
+	{
+		name:       "kubectl (POSIX shell)",
+		fileName:   "create-image-pull-secret.sh",
+		extensions: []string{".sh"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.KubectlCommand(ShellPOSIX)
+		},
+	},
+	{
+		name:       "kubectl (PowerShell)",
+		fileName:   "create-image-pull-secret.ps1",
+		extensions: []string{".ps1"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.KubectlCommand(ShellPowerShell)
+		},
+	},
+	{
+		name:       "Terraform",
+		fileName:   "image-pull-secret.tf",
+		extensions: []string{".tf"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.TerraformResource(g.formatOption(optTerraformPassword))
+		},
+		options: passwordVariableOptions("Terraform Options", optTerraformPassword),
+	},
+	{
+		name:       "Ansible",
+		fileName:   "image-pull-secret.yml",
+		extensions: []string{".yml", ".yaml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.AnsibleTasks(g.formatOption(optAnsiblePassword), g.yamlIndent())
+		},
+		options: passwordVariableOptions("Ansible Options", optAnsiblePassword),
+	},
+	{
+		name:       "GitLab CI (DOCKER_AUTH_CONFIG)",
+		fileName:   "docker-auth-config.json",
+		extensions: []string{".json"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.DockerAuthConfig()
+		},
+		check: GitLabMaskWarning,
+	},
+	{
+		name:       "containerd (config.toml)",
+		fileName:   "containerd-registry-auth.toml",
+		extensions: []string{".toml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.ContainerdAuthConfig()
+		},
+	},
+	{
+		name:       "K3s (registries.yaml)",
+		fileName:   "registries.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.K3sRegistriesConfig(g.yamlIndent())
+		},
+	},
+	{
+		name:       "kind (cluster config)",
+		fileName:   "kind-config.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.KindConfig(g.yamlIndent())
+		},
+	},
+	{
+		name:       "Argo CD (OCI repository)",
+		fileName:   "argocd-repository.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.ArgoCDRepository(g.ociRepositoryOptions())
+		},
+		options: (*generator).ociOptions,
+	},
+	{
+		name:       "Flux (OCIRepository)",
+		fileName:   "flux-oci-repository.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.FluxSource(KindOCIRepository, g.ociRepositoryOptions())
+		},
+		options: (*generator).ociOptions,
+	},
+	{
+		name:       "Flux (HelmRepository)",
+		fileName:   "flux-helm-repository.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.FluxSource(KindHelmRepository, g.ociRepositoryOptions())
+		},
+		options: (*generator).ociOptions,
+	},
+	{
+		name:       "Build credentials (Kaniko/BuildKit)",
+		fileName:   "build-credentials.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.BuildSecret(BuildSecretOptions{
+				Key:         g.formatOption(optBuildKey),
+				MountPath:   g.formatOption(optBuildMountPath),
+				WithSnippet: g.formatOption(optBuildSnippet) == "true",
+				Indent:      g.yamlIndent(),
+			})
+		},
+		options: (*generator).buildSecretOptions,
+		fanOut:  true,
+	},
+	{
+		name:       "ECR refresh (CronJob + RBAC)",
+		fileName:   "ecr-refresh.yaml",
+		extensions: []string{".yaml", ".yml"},
+		render: func(g *generator, s *Secret) (string, error) {
+			return s.ECRRefreshManifests(ECRRefreshOptions{
+				Region:            g.formatOption(optECRRegion),
+				Schedule:          g.formatOption(optECRSchedule),
+				CredentialsSecret: g.formatOption(optECRCredentialsSecret),
+				Indent:            g.yamlIndent(),
+			})
+		},
+		options: (*generator).ecrRefreshOptions,
+		fanOut:  true,
+	},
+}
+
+func outputFormatNames() []string {
+	names := make([]string, 0, len(outputFormats))
+	for _, f := range outputFormats {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// currentFormat returns the selected output format, falls back to the first one
+func (g *generator) currentFormat() outputFormat {
+	for _, f := range outputFormats {
+		if f.name == g.formatSelect.Selected {
+			return f
+		}
+	}
+	return outputFormats[0]
+}
+
+// renderOutput renders the current secret in the selected format and updates the output area
+func (g *generator) renderOutput() error {
+	if g.secret == nil {
+		return nil
+	}
+
+	format := g.currentFormat()
+	text, err := g.renderFormat(format)
+	if err != nil {
+		return err
+	}
+
+	if format.fanOut && len(g.namespaces) > 1 {
+		g.outputHeader.Text = fmt.Sprintf("%d Secrets", len(g.namespaces))
+	} else {
+		g.outputHeader.Text = DefaultOutputHeader
+	}
+	g.outputHeader.Refresh()
+
+	g.output.SetText(text)
+	g.window.Canvas().Refresh(g.output)
+
+	// shown below the header instead of a dialog, the output is rendered again on every format switch
+	warning := ""
+	if format.check != nil {
+		warning = format.check(text)
+	}
+	g.outputHint.SetText(warning)
+	if warning != "" {
+		g.outputHint.Show()
+	} else {
+		g.outputHint.Hide()
+	}
+
+	if format.decodable {
+		g.decodeBtn.Enable()
+	} else {
+		g.decodeBtn.Disable()
+	}
+	g.saveBtn.Enable()
+	g.copyBtn.Enable()
+	g.clearOutputBtn.Enable()
+
+	return nil
+}
+
+// renderFormat renders the current secret, formats with fan-out once per selected namespace
+func (g *generator) renderFormat(format outputFormat) (string, error) {
+	render := func(s *Secret) (string, error) {
+		return format.render(g, s)
+	}
+
+	if !format.fanOut {
+		if len(g.namespaces) > 1 {
+			return "", fmt.Errorf("the format %s supports only one namespace", format.name)
+		}
+		return render(g.secret)
+	}
+
+	withNamespaces := g.formatOption(optNamespaceManifests) == "true"
+	if len(g.namespaces) <= 1 && !withNamespaces {
+		return render(g.secret)
+	}
+
+	return g.secret.FanOut(g.namespaces, g.namespaceNames, withNamespaces, g.yamlIndent(), render)
+}
+
+// onFormatChanged re-renders an already generated secret in the new format
+func (g *generator) onFormatChanged(string) {
+	if g.currentFormat().options != nil {
+		g.formatOptionsBtn.Enable()
+	} else {
+		g.formatOptionsBtn.Disable()
+	}
+
+	if g.secret == nil {
+		return
+	}
+
+	if err := g.renderOutput(); err != nil {
+		dialog.ShowError(err, g.window)
+		g.clearOutput()
+	}
+}
+
+// yamlIndent is the YAML indentation of the settings, used by every YAML format
+func (g *generator) yamlIndent() int {
+	return g.appSettings.EncodeOptions().Indent
+}
+
+func (g *generator) formatOption(key string) string {
+	return g.appSettings.FormatOptions[key]
+}
+
+func (g *generator) setFormatOption(key, value string) {
+	if g.appSettings.FormatOptions == nil {
+		g.appSettings.FormatOptions = map[string]string{}
+	}
+	g.appSettings.FormatOptions[key] = strings.TrimSpace(value)
+}
+
+func (g *generator) showFormatOptions() {
+	if options := g.currentFormat().options; options != nil {
+		options(g, func() { g.onFormatChanged(g.formatSelect.Selected) })
+	}
+}
+
+func (g *generator) renderSecretYAML(s *Secret) (string, error) {
+	if g.isDecoded {
+		return s.DecodeDockerConfig(g.yamlIndent())
+	}
+	return s.ToYAMLWith(g.appSettings.EncodeOptions())
+}
+
+func (g *generator) renderSOPS(s *Secret) (string, error) {
+	recipients := g.formatOption(optSOPSRecipients)
+	if recipients == "" {
+		return "", fmt.Errorf("no age recipients configured, set them in the format options")
+	}
+	return s.ToSOPS(recipients, g.yamlIndent())
+}
+
+func (g *generator) sopsOptions(onSaved func()) {
+	recipientsEntry := widget.NewMultiLineEntry()
+	recipientsEntry.SetPlaceHolder("age1... (one recipient per line)")
+	recipientsEntry.SetText(g.formatOption(optSOPSRecipients))
+	recipientsEntry.SetMinRowsVisible(4)
+	recipientsEntry.Validator = func(s string) error {
+		_, err := ParseAgeRecipients(s)
+		return err
+	}
+
+	form := dialog.NewForm("SOPS Options", "Save", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Age Recipients", recipientsEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			g.setFormatOption(optSOPSRecipients, recipientsEntry.Text)
+			onSaved()
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(520, 260))
+	form.Show()
+}
+
+func (g *generator) renderExternalSecret(s *Secret) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", err
+	}
+
+	externalSecret, err := NewExternalSecret(registry, auth.Username, s.Metadata.Name, s.Metadata.Namespace,
+		ExternalSecretOptions{
+			StoreName:        g.formatOption(optESOStoreName),
+			StoreKind:        g.formatOption(optESOStoreKind),
+			UsernameKey:      g.formatOption(optESOUsernameKey),
+			UsernameProperty: g.formatOption(optESOUsernameProperty),
+			PasswordKey:      g.formatOption(optESOPasswordKey),
+			PasswordProperty: g.formatOption(optESOPasswordProperty),
+			RefreshInterval:  g.formatOption(optESORefreshInterval),
+		})
+	if err != nil {
+		return "", fmt.Errorf("%w, set it in the format options", err)
+	}
+
+	return externalSecret.ToYAML(g.yamlIndent())
+}
+
+func (g *generator) externalSecretOptions(onSaved func()) {
+	newEntry := func(key, placeHolder string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(placeHolder)
+		entry.SetText(g.formatOption(key))
+		return entry
+	}
+
+	storeNameEntry := newEntry(optESOStoreName, "e.g. vault-backend")
+	storeNameEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("store name is required")
+		}
+		return nil
+	}
+
+	storeKindSelect := widget.NewSelect([]string{KindClusterSecretStore, KindSecretStore}, nil)
+	storeKindSelect.SetSelected(g.formatOption(optESOStoreKind))
+	if storeKindSelect.Selected == "" {
+		storeKindSelect.SetSelectedIndex(0)
+	}
+
+	usernameKeyEntry := newEntry(optESOUsernameKey, "optional, form username if empty")
+	usernamePropertyEntry := newEntry(optESOUsernameProperty, "optional")
+	passwordKeyEntry := newEntry(optESOPasswordKey, "e.g. registry/gitlab")
+	passwordKeyEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("remote key is required")
+		}
+		return nil
+	}
+	passwordPropertyEntry := newEntry(optESOPasswordProperty, "optional")
+	refreshIntervalEntry := newEntry(optESORefreshInterval, DefaultRefreshInterval)
+
+	form := dialog.NewForm("ExternalSecret Options", "Save", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Store Name", storeNameEntry),
+			widget.NewFormItem("Store Kind", storeKindSelect),
+			widget.NewFormItem("Username Key", usernameKeyEntry),
+			widget.NewFormItem("Username Property", usernamePropertyEntry),
+			widget.NewFormItem("Password Key", passwordKeyEntry),
+			widget.NewFormItem("Password Property", passwordPropertyEntry),
+			widget.NewFormItem("Refresh Interval", refreshIntervalEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			g.setFormatOption(optESOStoreName, storeNameEntry.Text)
+			g.setFormatOption(optESOStoreKind, storeKindSelect.Selected)
+			g.setFormatOption(optESOUsernameKey, usernameKeyEntry.Text)
+			g.setFormatOption(optESOUsernameProperty, usernamePropertyEntry.Text)
+			g.setFormatOption(optESOPasswordKey, passwordKeyEntry.Text)
+			g.setFormatOption(optESOPasswordProperty, passwordPropertyEntry.Text)
+			g.setFormatOption(optESORefreshInterval, refreshIntervalEntry.Text)
+			onSaved()
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(520, 420))
+	form.Show()
+}
+
+// passwordVariableOptions lets the user replace the literal password with a variable placeholder
+func passwordVariableOptions(title, key string) func(g *generator, onSaved func()) {
+	return func(g *generator, onSaved func()) {
+		variableEntry := widget.NewEntry()
+		variableEntry.SetPlaceHolder("e.g. registry_password (empty for the literal password)")
+		variableEntry.SetText(g.formatOption(key))
+		variableEntry.Validator = func(s string) error {
+			if s != "" && !IsVariableNameValid(s) {
+				return fmt.Errorf("invalid variable name")
+			}
+			return nil
+		}
+
+		form := dialog.NewForm(title, "Save", "Cancel",
+			[]*widget.FormItem{
+				widget.NewFormItem("Password Variable", variableEntry),
+			},
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				g.setFormatOption(key, variableEntry.Text)
+				onSaved()
+			},
+			g.window,
+		)
+		form.Resize(fyne.NewSize(520, 200))
+		form.Show()
+	}
+}
+
+func (g *generator) ociRepositoryOptions() OCIRepositoryOptions {
+	return OCIRepositoryOptions{
+		Path:     g.formatOption(optOCIRepositoryPath),
+		Interval: g.formatOption(optFluxInterval),
+		Tag:      g.formatOption(optFluxTag),
+		Indent:   g.yamlIndent(),
+	}
+}
+
+// ociOptions configures the OCI repository shared by the Argo CD and Flux formats
+func (g *generator) ociOptions(onSaved func()) {
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("e.g. charts/podinfo (optional)")
+	pathEntry.SetText(g.formatOption(optOCIRepositoryPath))
+
+	intervalEntry := widget.NewEntry()
+	intervalEntry.SetPlaceHolder(DefaultFluxInterval)
+	intervalEntry.SetText(g.formatOption(optFluxInterval))
+
+	tagEntry := widget.NewEntry()
+	tagEntry.SetPlaceHolder(DefaultFluxTag)
+	tagEntry.SetText(g.formatOption(optFluxTag))
+
+	form := dialog.NewForm("OCI Repository Options", "Save", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Repository Path", pathEntry),
+			widget.NewFormItem("Flux Interval", intervalEntry),
+			widget.NewFormItem("Flux OCI Tag", tagEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			g.setFormatOption(optOCIRepositoryPath, pathEntry.Text)
+			g.setFormatOption(optFluxInterval, intervalEntry.Text)
+			g.setFormatOption(optFluxTag, tagEntry.Text)
+			onSaved()
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(520, 280))
+	form.Show()
+}
+
+func (g *generator) buildSecretOptions(onSaved func()) {
+	keyEntry := widget.NewEntry()
+	keyEntry.SetPlaceHolder(DefaultBuildSecretKey)
+	keyEntry.SetText(g.formatOption(optBuildKey))
+	keyEntry.Validator = func(s string) error {
+		if s != "" && !utils.IsSecretKeyValid(s) {
+			return fmt.Errorf("invalid secret data key")
+		}
+		return nil
+	}
+
+	mountPathEntry := widget.NewEntry()
+	mountPathEntry.SetPlaceHolder(DefaultBuildMountPath)
+	mountPathEntry.SetText(g.formatOption(optBuildMountPath))
+
+	snippetCheck := widget.NewCheck("Append volume/volumeMount example", nil)
+	snippetCheck.SetChecked(g.formatOption(optBuildSnippet) == "true")
+
+	form := dialog.NewForm("Build Credentials Options", "Save", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Data Key", keyEntry),
+			widget.NewFormItem("Mount Path", mountPathEntry),
+			widget.NewFormItem("", snippetCheck),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			g.setFormatOption(optBuildKey, keyEntry.Text)
+			g.setFormatOption(optBuildMountPath, mountPathEntry.Text)
+			g.setFormatOption(optBuildSnippet, strconv.FormatBool(snippetCheck.Checked))
+			onSaved()
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(520, 280))
+	form.Show()
+}
+
+func (g *generator) buildFormatSelect() {
+	formatSelect := widget.NewSelect(outputFormatNames(), nil)
+	if slices.Contains(formatSelect.Options, g.appSettings.DefaultFormat) {
+		formatSelect.SetSelected(g.appSettings.DefaultFormat)
+	} else {
+		formatSelect.SetSelectedIndex(0)
+	}
+
+	formatOptionsBtn := widget.NewButtonWithIcon("", theme.SettingsIcon(), g.showFormatOptions)
+
+	g.formatSelect = formatSelect
+	g.formatOptionsBtn = formatOptionsBtn
+
+	if g.currentFormat().options == nil {
+		formatOptionsBtn.Disable()
+	}
+
+	// set the callback after the initial selection, the options button does not exist before
+	formatSelect.OnChanged = g.onFormatChanged
+}
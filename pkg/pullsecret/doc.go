@@ -0,0 +1,23 @@
+// Package pullsecret creates, validates, parses and encodes Kubernetes image pull secrets
+// of type kubernetes.io/dockerconfigjson.
+//
+// The package has no GUI dependencies and can be used by other tools:
+//
+//	secret, err := pullsecret.New("ghcr.io", "ci-bot", token,
+//		pullsecret.WithName("ghcr-pull"),
+//		pullsecret.WithNamespace("apps"),
+//	)
+//	if err != nil {
+//		var verr *pullsecret.ValidationError
+//		if errors.As(err, &verr) {
+//			// verr.Field tells which input is wrong
+//		}
+//	}
+//	manifest, err := secret.Encode()
+//
+// Invalid input is reported as *ValidationError, which wraps one of the sentinel errors
+// ErrRequired, ErrInvalid or ErrPlaceholder.
+//
+// A Policy holds the rules of an organization, e.g. forbidden namespaces or allowed registries.
+// Policy.Check returns the broken rules as Violations, which wrap ErrPolicy and are errors or warnings.
+package pullsecret
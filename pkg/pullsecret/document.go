@@ -0,0 +1,296 @@
+package pullsecret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is one YAML document of a manifest file. It is edited on the YAML nodes, so fields the
+// Secret type does not know, like annotations, and comments are kept when the file is written again.
+type document struct {
+	// 1-based position in the file
+	index int
+	root  *yaml.Node
+	// the top level mapping, nil for empty documents and other YAML values
+	node *yaml.Node
+	// set by set and remove, only changed documents are encoded again
+	changed bool
+}
+
+// manifest is a multi-document YAML file split at its document separators. Segments without
+// changed documents are written back byte for byte.
+type manifest struct {
+	docs     []*document
+	segments []segment
+}
+
+// segment is the original text between two "---" lines and its documents, usually one
+type segment struct {
+	raw  []byte
+	docs []*document
+}
+
+// parseManifest reads all documents of a multi-document YAML
+func parseManifest(data []byte) (*manifest, error) {
+	m := &manifest{}
+	for _, raw := range splitDocuments(data) {
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		seg := segment{raw: raw}
+		for {
+			var root yaml.Node
+			err := dec.Decode(&root)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", len(m.docs)+1, err)
+			}
+
+			doc := &document{index: len(m.docs) + 1, root: &root}
+			if len(root.Content) > 0 && root.Content[0].Kind == yaml.MappingNode {
+				doc.node = root.Content[0]
+			}
+			m.docs = append(m.docs, doc)
+			seg.docs = append(seg.docs, doc)
+		}
+		m.segments = append(m.segments, seg)
+	}
+	return m, nil
+}
+
+// splitDocuments splits the YAML before every "---" document separator line
+func splitDocuments(data []byte) [][]byte {
+	var segments [][]byte
+	start := 0
+	for i := 0; i < len(data); {
+		end := bytes.IndexByte(data[i:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += i + 1
+		}
+
+		line := bytes.TrimRight(data[i:end], "\r\n")
+		if i > start && bytes.HasPrefix(line, []byte("---")) && (len(line) == 3 || line[3] == ' ' || line[3] == '\t') {
+			segments = append(segments, data[start:i])
+			start = i
+		}
+		i = end
+	}
+	if start < len(data) {
+		segments = append(segments, data[start:])
+	}
+	return segments
+}
+
+// encode writes the manifest again. Unchanged segments keep their original text, changed documents
+// are encoded with the indentation of their segment.
+func (m *manifest) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, seg := range m.segments {
+		if !slices.ContainsFunc(seg.docs, func(d *document) bool { return d.changed }) {
+			buf.Write(seg.raw)
+			continue
+		}
+
+		var encoded bytes.Buffer
+		enc := yaml.NewEncoder(&encoded)
+		enc.SetIndent(detectIndent(seg.raw))
+		for _, doc := range seg.docs {
+			if err := enc.Encode(doc.root); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+
+		if bytes.HasPrefix(seg.raw, []byte("---")) {
+			buf.WriteString("---\n")
+		}
+		buf.Write(encoded.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// detectIndent returns the smallest indentation of the YAML text, DefaultIndent if nothing is indented
+func detectIndent(raw []byte) int {
+	indent := 0
+	for _, line := range bytes.Split(raw, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n == 0 || len(bytes.TrimSpace(trimmed)) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if indent == 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 2 {
+		return DefaultIndent
+	}
+	return indent
+}
+
+// isSecret reports whether the document is a Kubernetes Secret
+func (d *document) isSecret() bool {
+	return d.node != nil && d.get("kind") == Kind
+}
+
+// secret decodes the document
+func (d *document) secret() (*Secret, error) {
+	var s Secret
+	if err := d.node.Decode(&s); err != nil {
+		return nil, fmt.Errorf("document %d: %w", d.index, err)
+	}
+	return &s, nil
+}
+
+// get returns the scalar at the path of mapping keys, empty if it does not exist
+func (d *document) get(path ...string) string {
+	node := lookup(d.node, path...)
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// set replaces the scalar at the path, missing mappings are created
+func (d *document) set(value string, path ...string) {
+	node := d.node
+	for i, key := range path {
+		child := lookup(node, key)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if i == len(path)-1 {
+				child = &yaml.Node{Kind: yaml.ScalarNode}
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		node = child
+	}
+
+	d.changed = true
+	node.Kind = yaml.ScalarNode
+	node.Tag = "!!str"
+	node.Style = 0
+	node.Content = nil
+	node.Value = value
+}
+
+// remove deletes the last key of the path, an emptied mapping is removed as well
+func (d *document) remove(path ...string) {
+	parent := lookup(d.node, path[:len(path)-1]...)
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return
+	}
+
+	key := path[len(path)-1]
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			parent.Content = slices.Delete(parent.Content, i, i+2)
+			d.changed = true
+			break
+		}
+	}
+	if len(parent.Content) == 0 && len(path) > 1 {
+		d.remove(path[:len(path)-1]...)
+	}
+}
+
+// keys returns the keys of the mapping at the path
+func (d *document) keys(path ...string) []string {
+	node := lookup(d.node, path...)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+	return keys
+}
+
+// lookup follows the path of mapping keys, nil if a key does not exist
+func lookup(node *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+// rawDockerConfig is a docker config JSON which keeps the fields DockerConfig does not know,
+// e.g. credHelpers or an identitytoken
+type rawDockerConfig struct {
+	fields map[string]json.RawMessage
+	auths  map[string]map[string]json.RawMessage
+}
+
+// parseRawDockerConfig reads the plain JSON of a docker config
+func parseRawDockerConfig(data []byte) (*rawDockerConfig, error) {
+	cfg := &rawDockerConfig{auths: map[string]map[string]json.RawMessage{}}
+	if err := json.Unmarshal(data, &cfg.fields); err != nil {
+		return nil, err
+	}
+	if cfg.fields == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	if auths, ok := cfg.fields["auths"]; ok {
+		if err := json.Unmarshal(auths, &cfg.auths); err != nil {
+			return nil, fmt.Errorf("auths: %w", err)
+		}
+		if cfg.auths == nil {
+			cfg.auths = map[string]map[string]json.RawMessage{}
+		}
+	}
+	return cfg, nil
+}
+
+// registries returns the keys of the auths, sorted
+func (c *rawDockerConfig) registries() []string {
+	return slices.Sorted(maps.Keys(c.auths))
+}
+
+// entry returns a string field of the auth entry of a registry
+func (c *rawDockerConfig) entry(registry, field string) string {
+	var value string
+	_ = json.Unmarshal(c.auths[registry][field], &value)
+	return value
+}
+
+// setEntry sets a string field of the auth entry of a registry
+func (c *rawDockerConfig) setEntry(registry, field, value string) {
+	encoded, _ := json.Marshal(value)
+	c.auths[registry][field] = encoded
+}
+
+// marshal returns the docker config as compact JSON
+func (c *rawDockerConfig) marshal() ([]byte, error) {
+	fields := maps.Clone(c.fields)
+	auths, err := json.Marshal(c.auths)
+	if err != nil {
+		return nil, err
+	}
+	fields["auths"] = auths
+	return json.Marshal(fields)
+}
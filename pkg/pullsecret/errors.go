@@ -0,0 +1,54 @@
+package pullsecret
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors wrapped by ValidationError
+var (
+	ErrRequired    = errors.New("is required")
+	ErrInvalid     = errors.New("is invalid")
+	ErrPlaceholder = errors.New("contains a placeholder")
+	// wrapped by Violation, the input is valid but not allowed by a Policy
+	ErrPolicy = errors.New("is not allowed by the policy")
+)
+
+// Fields checked by the validation
+const (
+	FieldRegistry     = "registry"
+	FieldUsername     = "username"
+	FieldPassword     = "password"
+	FieldName         = "name"
+	FieldNamespace    = "namespace"
+	FieldLabels       = "labels"
+	FieldType         = "type"
+	FieldDockerConfig = "dockerconfigjson"
+)
+
+// ValidationError describes one invalid input of a pull secret
+type ValidationError struct {
+	// one of the Field constants
+	Field string
+	// the rejected value, always empty for the password
+	Value string
+	// human readable explanation
+	Reason string
+	// ErrRequired, ErrInvalid or ErrPlaceholder
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s %v: %s", e.Field, e.Err, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newValidationError(field, value string, err error, reason string) *ValidationError {
+	return &ValidationError{Field: field, Value: value, Err: err, Reason: reason}
+}
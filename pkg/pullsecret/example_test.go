@@ -0,0 +1,61 @@
+package pullsecret_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+func ExampleNew() {
+	secret, err := pullsecret.New("ghcr.io", "ci-bot", "token",
+		pullsecret.WithName("ghcr-pull"),
+		pullsecret.WithNamespace("apps"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	registry, auth, _ := secret.Credentials()
+	fmt.Println(secret.Metadata.Namespace, secret.Metadata.Name, registry, auth.Username)
+	// Output: apps ghcr-pull ghcr.io ci-bot
+}
+
+func ExampleValidationError() {
+	_, err := pullsecret.New("<account-id>.dkr.ecr.<region>.amazonaws.com", "AWS", "token")
+
+	var verr *pullsecret.ValidationError
+	if errors.As(err, &verr) {
+		fmt.Println(verr.Field, errors.Is(err, pullsecret.ErrPlaceholder))
+	}
+	// Output: registry true
+}
+
+func ExamplePolicy_Check() {
+	policy, err := pullsecret.ParsePolicy([]byte(`version: 1
+forbiddenNamespaces: [default, kube-system]
+namePattern: ^pull-[a-z0-9-]+$
+requiredLabels: [owner]
+allowedRegistries: [ghcr.io]
+severity:
+  requiredLabels: warning
+`))
+	if err != nil {
+		panic(err)
+	}
+
+	secret, err := pullsecret.New("ghcr.io", "ci-bot", "token",
+		pullsecret.WithName("pull-ghcr"),
+		pullsecret.WithNamespace("default"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	violations := policy.Check(secret)
+	fmt.Println(violations.Err())
+	fmt.Println(violations.Warnings()[0].Reason)
+	// Output:
+	// namespace is not allowed by the policy: no pull secrets in namespace default
+	// label owner is required
+}
@@ -0,0 +1,94 @@
+package pullsecret
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxLabelNameLength is the maximum length of a label value and of the name part of a label key
+	MaxLabelNameLength = 63
+	// MaxLabelPrefixLength is the maximum length of the optional DNS prefix of a label key
+	MaxLabelPrefixLength = 253
+)
+
+var labelNameRegex = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// ValidateLabels checks the label keys and values against the rules of Kubernetes: a key is an optional
+// DNS subdomain prefix with '/' and a name of at most 63 characters, a value is empty or a name.
+func ValidateLabels(labels map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		if reason := checkLabelKey(key); reason != "" {
+			return newValidationError(FieldLabels, key, ErrInvalid, fmt.Sprintf("label key %q %s", key, reason))
+		}
+		if value := labels[key]; value != "" {
+			if reason := checkLabelName(value); reason != "" {
+				return newValidationError(FieldLabels, value, ErrInvalid, fmt.Sprintf("value of label %q %s", key, reason))
+			}
+		}
+	}
+	return nil
+}
+
+// ParseLabels reads comma separated key=value pairs like "owner=team-a, env=prod" and validates them,
+// an empty string returns no labels
+func ParseLabels(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, newValidationError(FieldLabels, pair, ErrInvalid, fmt.Sprintf("expected key=value, found %q", pair))
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return labels, nil
+}
+
+// FormatLabels returns the labels sorted by key as comma separated key=value pairs, the input of ParseLabels
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// checkLabelKey returns the first violated rule of a label key, an empty string if the key is valid
+func checkLabelKey(key string) string {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		return checkLabelName(key)
+	}
+	if reason := checkDNS1123(prefix, MaxLabelPrefixLength, true); reason != "" {
+		return "has a prefix which " + reason
+	}
+	return checkLabelName(name)
+}
+
+// checkLabelName returns the first violated rule of a label name or value
+func checkLabelName(name string) string {
+	switch {
+	case name == "":
+		return "must not be empty"
+	case len(name) > MaxLabelNameLength:
+		return fmt.Sprintf("must be no more than %d characters, has %d", MaxLabelNameLength, len(name))
+	case !labelNameRegex.MatchString(name):
+		return "must consist of alphanumeric characters, '-', '_' or '.' and start and end alphanumeric"
+	}
+	return ""
+}
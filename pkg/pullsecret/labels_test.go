@@ -0,0 +1,37 @@
+package pullsecret
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels(" owner=team-a, app.kubernetes.io/part-of = shop,empty=, ")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "team-a", "app.kubernetes.io/part-of": "shop", "empty": ""}, labels)
+	assert.Equal(t, "app.kubernetes.io/part-of=shop, empty=, owner=team-a", FormatLabels(labels))
+
+	labels, err = ParseLabels(" ")
+	assert.NoError(t, err)
+	assert.Nil(t, labels)
+
+	tests := map[string]string{
+		"owner":                            `labels is invalid: expected key=value, found "owner"`,
+		"=team-a":                          `labels is invalid: label key "" must not be empty`,
+		"Example.com/owner=a":              `labels is invalid: label key "Example.com/owner" has a prefix which must consist of lowercase characters`,
+		"owner=team a":                     `labels is invalid: value of label "owner" must consist of alphanumeric characters, '-', '_' or '.' and start and end alphanumeric`,
+		"owner=" + strings.Repeat("a", 64): `labels is invalid: value of label "owner" must be no more than 63 characters, has 64`,
+	}
+	for input, msg := range tests {
+		_, err := ParseLabels(input)
+		assert.ErrorIs(t, err, ErrInvalid, input)
+		assert.EqualError(t, err, msg, input)
+	}
+}
+
+func TestNew_InvalidLabels(t *testing.T) {
+	_, err := New("ghcr.io", "ci", "token", WithLabels(map[string]string{"-owner": "a"}))
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,408 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rules of the linter, used as Finding.Rule
+const (
+	LintAPIVersion   = "apiVersion"
+	LintMetadata     = "metadata"
+	LintType         = "type"
+	LintDataKey      = "dataKey"
+	LintEncoding     = "encoding"
+	LintDockerConfig = "dockerConfig"
+	LintScheme       = "registryScheme"
+	LintUsername     = "usernameColon"
+	LintAuth         = "auth"
+)
+
+// DockerHubLegacyKey is the registry key of Docker Hub written by docker login, the only key with scheme
+// and path which is expected as it is
+const DockerHubLegacyKey = "https://index.docker.io/v1/"
+
+// legacyDataKey is the data key of secrets of type kubernetes.io/dockercfg
+const legacyDataKey = ".dockercfg"
+
+// Finding is one problem of a pull secret manifest
+type Finding struct {
+	// 1-based position of the YAML document in the file
+	Document int
+	// name of the secret, empty if it has none
+	Name string
+	// one of the Lint rules
+	Rule     string
+	Severity Severity
+	// key of the auths entry, empty for problems of the whole secret
+	Registry string
+	Message  string
+	// corrected in the manifest returned by LintResult.Fixed
+	Fixable bool
+}
+
+func (f Finding) String() string {
+	location := fmt.Sprintf("document %d", f.Document)
+	if f.Name != "" {
+		location += " (" + f.Name + ")"
+	}
+	if f.Registry != "" {
+		location += " " + f.Registry
+	}
+
+	s := fmt.Sprintf("%s: %s %s: %s", location, f.Severity, f.Rule, f.Message)
+	if f.Fixable {
+		s += " [fixable]"
+	}
+	return s
+}
+
+// LintResult are the findings of all pull secrets of a manifest file
+type LintResult struct {
+	Findings []Finding
+	// number of secrets in the file, documents of other kinds are skipped
+	Secrets int
+
+	manifest *manifest
+	changed  bool
+}
+
+// Errors returns the number of findings with severity error
+func (r *LintResult) Errors() int {
+	return r.count(SeverityError, false)
+}
+
+// Warnings returns the number of findings with severity warning
+func (r *LintResult) Warnings() int {
+	return r.count(SeverityWarning, false)
+}
+
+// Remaining returns the number of errors the corrected manifest still has
+func (r *LintResult) Remaining() int {
+	return r.count(SeverityError, true)
+}
+
+func (r *LintResult) count(severity Severity, unfixableOnly bool) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity && (!unfixableOnly || !f.Fixable) {
+			n++
+		}
+	}
+	return n
+}
+
+// Changed reports whether any finding is fixable
+func (r *LintResult) Changed() bool {
+	return r.changed
+}
+
+// Fixed returns the manifest with all fixable findings corrected. Documents without fixes keep their
+// original text, the fixed ones keep unknown fields, comments and the indentation of the file.
+func (r *LintResult) Fixed() ([]byte, error) {
+	return r.manifest.encode()
+}
+
+// Lint checks every Secret of a multi-document YAML for the typical faults of hand-written pull secrets:
+// a wrong type or data key, base64 over already base64 encoded content, an auth which does not match
+// username:password, a colon in the username or a registry key with scheme. Only YAML which can not be
+// parsed returns an error.
+func Lint(data []byte) (*LintResult, error) {
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LintResult{manifest: m}
+	for _, doc := range m.docs {
+		if !doc.isSecret() {
+			continue
+		}
+		result.Secrets++
+
+		l := &linter{doc: doc}
+		l.lint()
+		result.Findings = append(result.Findings, l.findings...)
+		result.changed = result.changed || l.changed
+	}
+	return result, nil
+}
+
+// linter checks and corrects one secret document
+type linter struct {
+	doc      *document
+	name     string
+	findings []Finding
+	changed  bool
+}
+
+func (l *linter) report(rule string, severity Severity, registry string, fixable bool, format string, args ...any) {
+	l.findings = append(l.findings, Finding{
+		Document: l.doc.index,
+		Name:     l.name,
+		Rule:     rule,
+		Severity: severity,
+		Registry: registry,
+		Message:  fmt.Sprintf(format, args...),
+		Fixable:  fixable,
+	})
+	l.changed = l.changed || fixable
+}
+
+func (l *linter) lint() {
+	secret, err := l.doc.secret()
+	if err != nil {
+		l.report(LintMetadata, SeverityError, "", false, "%v", err)
+		return
+	}
+	l.name = secret.Metadata.Name
+
+	if secret.APIVersion != APIVersion {
+		l.report(LintAPIVersion, SeverityError, "", true, "apiVersion is %q, expected %s", secret.APIVersion, APIVersion)
+		l.doc.set(APIVersion, "apiVersion")
+	}
+	if err := validateMetadata(secret.Metadata); err != nil {
+		l.report(LintMetadata, SeverityError, "", false, "%v", err)
+	}
+
+	source, found := l.findDockerConfig(secret)
+	if secret.Type != TypeDockerConfigJSON {
+		reason := "the type of an existing secret can not be changed, delete it before applying the fix"
+		if !found {
+			reason = "no docker config found to fix it"
+		}
+		l.report(LintType, SeverityError, "", found, "type is %q, expected %s, %s", secret.Type, TypeDockerConfigJSON, reason)
+		if found {
+			l.doc.set(TypeDockerConfigJSON, "type")
+		}
+	}
+	if !found {
+		return
+	}
+
+	configChanged := source.changed
+	if len(source.cfg.auths) == 0 {
+		l.report(LintDockerConfig, SeverityError, "", false, "the docker config has no registry")
+	}
+	for _, registry := range source.cfg.registries() {
+		if l.lintEntry(source.cfg, registry) {
+			configChanged = true
+		}
+	}
+
+	if configChanged {
+		l.writeDockerConfig(source)
+	}
+}
+
+// configSource is where the docker config of a secret was found
+type configSource struct {
+	// data or stringData
+	section string
+	key     string
+	cfg     *rawDockerConfig
+	// the docker config must be written again, e.g. under another key
+	changed bool
+}
+
+// findDockerConfig looks for the docker config under the expected key first, then under any other key
+func (l *linter) findDockerConfig(secret *Secret) (*configSource, bool) {
+	type candidate struct{ section, key, value string }
+	var candidates []candidate
+	for _, section := range []string{"data", "stringData"} {
+		if value, ok := sectionValues(secret, section)[DataKey]; ok {
+			candidates = append(candidates, candidate{section, DataKey, value})
+		}
+	}
+	for _, section := range []string{"data", "stringData"} {
+		values := sectionValues(secret, section)
+		for _, key := range l.doc.keys(section) {
+			if key != DataKey {
+				candidates = append(candidates, candidate{section, key, values[key]})
+			}
+		}
+	}
+
+	for _, c := range candidates {
+		plain, encodingProblem, err := decodeConfigValue(c.section, c.value)
+		var cfg *rawDockerConfig
+		legacy := false
+		if err == nil {
+			cfg, legacy, err = parseAnyDockerConfig(plain)
+		}
+		if err != nil {
+			// only the expected key is reported, other keys may hold anything
+			if c.key == DataKey {
+				l.report(LintDockerConfig, SeverityError, "", false, "%s %s: %v", c.section, DataKey, err)
+				return nil, false
+			}
+			continue
+		}
+
+		source := &configSource{section: c.section, key: c.key, cfg: cfg}
+		if encodingProblem != "" {
+			l.report(LintEncoding, SeverityError, "", true, "%s %s %s", c.section, c.key, encodingProblem)
+			source.changed = true
+		}
+		if c.key != DataKey {
+			l.report(LintDataKey, SeverityError, "", true, "the docker config is stored under %s %q, expected %s", c.section, c.key, DataKey)
+			source.changed = true
+		}
+		if legacy {
+			l.report(LintDataKey, SeverityWarning, "", true, "the docker config has the legacy %s format without auths", legacyDataKey)
+			source.changed = true
+		}
+		return source, true
+	}
+
+	l.report(LintDockerConfig, SeverityError, "", false, "no docker config found, expected %s in data", DataKey)
+	return nil, false
+}
+
+// lintEntry checks the auth entry of one registry and corrects it in the config, reports whether it changed
+func (l *linter) lintEntry(cfg *rawDockerConfig, registry string) bool {
+	changed := false
+	username := cfg.entry(registry, "username")
+	password := cfg.entry(registry, "password")
+	auth := cfg.entry(registry, "auth")
+
+	if strings.Contains(registry, "://") && registry != DockerHubLegacyKey {
+		_, host, _ := strings.Cut(registry, "://")
+		host = strings.TrimRight(host, "/")
+		_, exists := cfg.auths[host]
+		fixable := host != "" && !exists
+		l.report(LintScheme, SeverityWarning, registry, fixable, "registry keys are matched without scheme, expected %q", host)
+		if fixable {
+			cfg.auths[host] = cfg.auths[registry]
+			delete(cfg.auths, registry)
+			registry = host
+			changed = true
+		}
+	}
+	if HasPlaceholder(registry) {
+		l.report(LintDockerConfig, SeverityError, registry, false, "the registry contains a placeholder")
+	}
+
+	if strings.Contains(username, ":") {
+		// "username:password" entered as username
+		fixable := password == ""
+		l.report(LintUsername, SeverityError, registry, fixable, "the username must not contain ':', the auth can not be split again")
+		if fixable {
+			username, password, _ = strings.Cut(username, ":")
+			cfg.setEntry(registry, "username", username)
+			cfg.setEntry(registry, "password", password)
+			changed = true
+		}
+	}
+
+	hasCredentials := username != "" && password != ""
+	expected := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	decoded, decodeErr := base64.StdEncoding.DecodeString(auth)
+	switch {
+	case auth == "" && hasCredentials:
+		l.report(LintAuth, SeverityWarning, registry, true, "auth is missing, some tools only read auth")
+	case auth == "" && username == "" && password == "":
+		l.report(LintAuth, SeverityError, registry, false, "the entry has neither auth nor username and password")
+		return changed
+	case auth == "":
+		l.report(LintAuth, SeverityError, registry, false, "username and password are both required without auth")
+		return changed
+	case decodeErr != nil:
+		l.report(LintAuth, SeverityError, registry, hasCredentials, "auth is no valid base64")
+	case hasCredentials && string(decoded) != username+":"+password:
+		l.report(LintAuth, SeverityError, registry, true, "auth does not match username:password")
+	case !hasCredentials && !strings.Contains(string(decoded), ":"):
+		l.report(LintAuth, SeverityError, registry, false, "auth must be base64 of username:password")
+		return changed
+	default:
+		// an entry with only auth is what kubelet, containerd and docker read anyway
+		return changed
+	}
+
+	if hasCredentials {
+		cfg.setEntry(registry, "auth", expected)
+		changed = true
+	}
+	return changed
+}
+
+// writeDockerConfig stores the corrected docker config under the expected key of its section
+func (l *linter) writeDockerConfig(source *configSource) {
+	plain, err := source.cfg.marshal()
+	if err != nil {
+		l.report(LintDockerConfig, SeverityError, "", false, "%v", err)
+		return
+	}
+
+	l.doc.remove(source.section, source.key)
+	if source.section == "stringData" {
+		l.doc.set(string(plain), "stringData", DataKey)
+		return
+	}
+	l.doc.set(base64.StdEncoding.EncodeToString(plain), "data", DataKey)
+}
+
+func sectionValues(secret *Secret, section string) map[string]string {
+	if section == "stringData" {
+		return secret.StringData
+	}
+	return secret.Data
+}
+
+// decodeConfigValue returns the plain JSON of a data (base64) or stringData (plain) value and describes
+// a wrong encoding which was undone
+func decodeConfigValue(section, value string) ([]byte, string, error) {
+	value = strings.TrimSpace(value)
+
+	if section == "stringData" {
+		if json.Valid([]byte(value)) {
+			return []byte(value), "", nil
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil && json.Valid(decoded) {
+			return decoded, "is base64 encoded, stringData expects plain JSON", nil
+		}
+		return nil, "", fmt.Errorf("no valid JSON")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if json.Valid([]byte(value)) {
+			return []byte(value), "is plain JSON, data expects base64", nil
+		}
+		return nil, "", fmt.Errorf("no valid base64: %w", err)
+	}
+	if json.Valid(decoded) {
+		return decoded, "", nil
+	}
+	if twice, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(decoded))); err == nil && json.Valid(twice) {
+		return twice, "is base64 encoded twice", nil
+	}
+	return nil, "", fmt.Errorf("no valid JSON")
+}
+
+// parseAnyDockerConfig reads a docker config with auths or in the legacy .dockercfg format, which is
+// converted to auths
+func parseAnyDockerConfig(plain []byte) (*rawDockerConfig, bool, error) {
+	cfg, err := parseRawDockerConfig(plain)
+	if err != nil {
+		return nil, false, fmt.Errorf("no valid docker config: %w", err)
+	}
+	if _, ok := cfg.fields["auths"]; ok {
+		return cfg, false, nil
+	}
+
+	// legacy format: the registries are the top level keys
+	legacy := &rawDockerConfig{fields: map[string]json.RawMessage{}, auths: map[string]map[string]json.RawMessage{}}
+	if err := json.Unmarshal(plain, &legacy.auths); err != nil || len(legacy.auths) == 0 {
+		return nil, false, fmt.Errorf("no docker config, auths is missing")
+	}
+	for _, entry := range legacy.auths {
+		if !slices.ContainsFunc([]string{"auth", "username"}, func(field string) bool { _, ok := entry[field]; return ok }) {
+			return nil, false, fmt.Errorf("no docker config, auths is missing")
+		}
+	}
+	return legacy, true, nil
+}
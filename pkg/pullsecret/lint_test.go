@@ -0,0 +1,183 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestLint_Valid(t *testing.T) {
+	secret, err := New("ghcr.io", "ci", "token", WithName("ghcr-pull"))
+	assert.NoError(t, err)
+	manifest, err := secret.Encode()
+	assert.NoError(t, err)
+
+	result, err := Lint(append([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n---\n"), manifest...))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Secrets)
+	assert.Empty(t, result.Findings)
+	assert.False(t, result.Changed())
+}
+
+func TestLint_Fix(t *testing.T) {
+	wrongAuth := `{"auths":{"https://ghcr.io/":{"username":"ci","password":"token","auth":"` + b64("ci:old") + `","identitytoken":"keep"}},"credHelpers":{"gcr.io":"gcloud"}}`
+	manifest := `# team a
+apiVersion: v1
+kind: Secret
+metadata:
+  name: ghcr-pull
+  annotations:
+    owner: team-a
+type: Opaque
+data:
+  dockerconfigjson: ` + b64(b64(wrongAuth)) + `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quay-pull
+type: kubernetes.io/dockerconfigjson
+stringData:
+  .dockerconfigjson: '{"auths":{"quay.io":{"username":"bot:secret"}}}'
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: Broken
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: not-base64
+`
+
+	result, err := Lint([]byte(manifest))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Secrets)
+
+	var rules []string
+	for _, f := range result.Findings {
+		rules = append(rules, f.Rule)
+	}
+	assert.Equal(t, []string{
+		LintEncoding, LintDataKey, LintType, LintScheme, LintAuth,
+		LintUsername, LintAuth,
+		LintMetadata, LintDockerConfig,
+	}, rules)
+	assert.Equal(t, "document 1 (ghcr-pull) ghcr.io: error auth: auth does not match username:password [fixable]", result.Findings[4].String())
+	assert.Equal(t, 2, result.Remaining())
+	assert.Equal(t, 2, result.Warnings())
+	assert.True(t, result.Changed())
+
+	fixed, err := Lint(mustFixed(t, result))
+	assert.NoError(t, err)
+	// only the problems which can not be fixed are left
+	assert.Len(t, fixed.Findings, 2)
+	for _, f := range fixed.Findings {
+		assert.Equal(t, 3, f.Document)
+	}
+
+	secrets, err := ParseAll(mustFixed(t, result))
+	assert.NoError(t, err)
+	assert.NoError(t, secrets[0].Validate())
+	assert.NoError(t, secrets[1].Validate())
+
+	registry, auth, err := secrets[1].Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io", registry)
+	assert.Equal(t, AuthEntry{Username: "bot", Password: "secret", Auth: b64("bot:secret")}, auth)
+
+	cfg, err := secrets[0].DockerConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, b64("ci:token"), cfg.Auths["ghcr.io"].Auth)
+
+	output := string(mustFixed(t, result))
+	assert.Contains(t, output, "# team a\n")
+	assert.Contains(t, output, "    owner: team-a\n")
+	assert.Contains(t, output, "  .dockerconfigjson: not-base64\n")
+	decoded, err := secrets[0].Decoded()
+	assert.NoError(t, err)
+	assert.Contains(t, decoded.Data[DataKey], `"identitytoken":"keep"`)
+	assert.Contains(t, decoded.Data[DataKey], `"credHelpers":{"gcr.io":"gcloud"}`)
+}
+
+func TestLint_Legacy(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: legacy
+type: kubernetes.io/dockercfg
+data:
+  .dockercfg: ` + b64(`{"registry.example.com":{"auth":"`+b64("bot:token")+`"}}`) + `
+`
+	result, err := Lint([]byte(manifest))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Remaining())
+
+	secret, err := Parse(mustFixed(t, result))
+	assert.NoError(t, err)
+	assert.NoError(t, secret.Validate())
+	assert.NotContains(t, secret.Data, ".dockercfg")
+}
+
+func TestLint_AuthOnly(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: auth-only
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: ` + b64(`{"auths":{"ghcr.io":{"auth":"`+b64("ci:token")+`"}}}`) + `
+`
+	result, err := Lint([]byte(manifest))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Findings)
+	assert.False(t, result.Changed())
+
+	secret, err := Parse([]byte(manifest))
+	assert.NoError(t, err)
+	assert.NoError(t, secret.Validate())
+}
+
+func TestLint_InvalidYAML(t *testing.T) {
+	_, err := Lint([]byte("kind: [Secret"))
+	assert.Error(t, err)
+}
+
+func mustFixed(t *testing.T, result *LintResult) []byte {
+	fixed, err := result.Fixed()
+	assert.NoError(t, err)
+	return fixed
+}
+
+func TestLint_FixKeepsOtherDocuments(t *testing.T) {
+	configMap := `apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: settings
+data:
+    key: "value"
+`
+	secret := `---
+apiVersion: v1
+kind: Secret
+metadata:
+    name: ghcr-pull
+type: Opaque
+data:
+    .dockerconfigjson: ` + b64(`{"auths":{"ghcr.io":{"username":"ci","password":"token","auth":"`+b64("ci:token")+`"}}}`) + `
+`
+
+	result, err := Lint([]byte(configMap + secret))
+	assert.NoError(t, err)
+	assert.True(t, result.Changed())
+
+	fixed, err := result.Fixed()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(fixed), configMap), "documents without fixes are byte-identical")
+	assert.Contains(t, string(fixed), "---\napiVersion: v1\nkind: Secret\nmetadata:\n    name: ghcr-pull\ntype: kubernetes.io/dockerconfigjson\n")
+}
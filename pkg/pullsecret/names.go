@@ -0,0 +1,154 @@
+package pullsecret
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mrand "math/rand"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var firstNames = []string{
+	"john", "james", "robert", "michael", "william",
+	"david", "richard", "joseph", "thomas", "charles",
+	"daniel", "matthew", "anthony", "mark", "steven",
+	"paul", "andrew", "joshua", "kevin", "brian",
+	"george", "edward", "timothy", "jason", "ryan",
+	"jacob", "nicholas", "eric", "jonathan", "justin",
+	"scott", "brandon", "benjamin", "samuel", "patrick",
+	"jack", "tyler", "aaron", "henry", "adam",
+	"nathan", "kyle", "jeremy", "sean", "ethan",
+	"noah", "jordan", "dylan", "gabriel", "vincent",
+
+	"oliver", "leo", "liam", "lucas", "felix",
+	"max", "emil", "anton", "leon", "lukas",
+	"tobias", "jonas", "simon", "fabian", "marco",
+	"sebastian", "manuel", "ivan", "nikola", "milan",
+	"stefano", "lorenzo", "giovanni", "pierre", "luc",
+	"antoine", "julien", "carlos", "miguel", "diego",
+	"javier", "antonio", "rafael", "pablo", "andres",
+	"oleg", "dmitri", "alexei", "roman", "kasper",
+	"anders", "lars", "mikkel", "henrik", "oskar", "christian",
+
+	"emma", "olivia", "ava", "sophia", "isabella",
+	"mia", "amelia", "charlotte", "harper", "evelyn",
+	"abigail", "emily", "elizabeth", "sofia", "avery",
+	"scarlett", "grace", "chloe", "victoria", "riley",
+	"arabella", "lily", "hannah", "ella", "nora",
+	"zoe", "lila", "clara", "julia", "sarah",
+	"maria", "anna", "kate", "paula", "laura",
+	"lucia", "isabel", "camila", "alice", "amelie",
+	"sophie", "leonie", "marie", "mia", "emilia",
+	"eva", "elena", "katharina", "lena", "julia",
+}
+
+var adjectives = []string{
+	"brave", "calm", "eager", "fancy", "gentle",
+	"happy", "jolly", "kind", "lucky", "mighty",
+	"nice", "proud", "quick", "sharp", "smart",
+	"sunny", "swift", "wise", "bold", "cool",
+}
+
+// generates a random hex suffix of given byte length
+func randomSuffix(bytes int) string {
+	b := make([]byte, bytes)
+	_, _ = crand.Read(b) // crypto/rand
+	return hex.EncodeToString(b)
+}
+
+// GenerateName returns a random, valid secret name like "pullsecret-brave-john-1a2b"
+func GenerateName() string {
+	adjective := adjectives[mrand.Intn(len(adjectives))]
+	name := firstNames[mrand.Intn(len(firstNames))]
+	suffix := randomSuffix(2) // 4 hex chars
+
+	return fmt.Sprintf(
+		"pullsecret-%s-%s-%s",
+		adjective,
+		name,
+		suffix,
+	)
+}
+
+const (
+	// MaxNameLength is the maximum length of a DNS-1123 subdomain, e.g. a secret name
+	MaxNameLength = 253
+	// MaxNamespaceLength is the maximum length of a DNS-1123 label, e.g. a namespace
+	MaxNamespaceLength = 63
+)
+
+var dns1123LabelRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidateName checks a secret name against the rules of a DNS-1123 subdomain: at most 253 characters,
+// lowercase alphanumeric characters, '-' or '.', every dot separated part starts and ends alphanumeric.
+// The returned *ValidationError explains which rule failed.
+func ValidateName(name string) error {
+	if reason := checkDNS1123(name, MaxNameLength, true); reason != "" {
+		return newValidationError(FieldName, name, ErrInvalid, reason)
+	}
+	return nil
+}
+
+// ValidateNamespace checks a namespace against the rules of a DNS-1123 label: at most 63 characters,
+// lowercase alphanumeric characters or '-', starting and ending alphanumeric.
+// The returned *ValidationError explains which rule failed.
+func ValidateNamespace(namespace string) error {
+	if reason := checkDNS1123(namespace, MaxNamespaceLength, false); reason != "" {
+		return newValidationError(FieldNamespace, namespace, ErrInvalid, reason)
+	}
+	return nil
+}
+
+// IsValidName reports whether name is a valid secret name, see ValidateName
+func IsValidName(name string) bool {
+	return ValidateName(name) == nil
+}
+
+// IsValidNamespace reports whether namespace is a valid namespace, see ValidateNamespace
+func IsValidNamespace(namespace string) bool {
+	return ValidateNamespace(namespace) == nil
+}
+
+// checkDNS1123 returns the first violated rule of a DNS-1123 subdomain (dots allowed) or label,
+// an empty string if the value is valid
+func checkDNS1123(value string, maxLength int, allowDots bool) string {
+	if value == "" {
+		return "must not be empty"
+	}
+	if length := utf8.RuneCountInString(value); length > maxLength {
+		return fmt.Sprintf("must be no more than %d characters, has %d", maxLength, length)
+	}
+
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+		case r == '.' && allowDots:
+		case r == '.':
+			return "must not contain '.'"
+		case r >= 'A' && r <= 'Z':
+			return "must consist of lowercase characters"
+		default:
+			if allowDots {
+				return fmt.Sprintf("contains %q, only lowercase alphanumeric characters, '-' and '.' are allowed", r)
+			}
+			return fmt.Sprintf("contains %q, only lowercase alphanumeric characters and '-' are allowed", r)
+		}
+	}
+
+	parts := []string{value}
+	if allowDots {
+		parts = strings.Split(value, ".")
+	}
+	for _, part := range parts {
+		if !dns1123LabelRegex.MatchString(part) {
+			if len(parts) > 1 {
+				return "every dot separated part must start and end with an alphanumeric character"
+			}
+			return "must start and end with an alphanumeric character"
+		}
+	}
+
+	return ""
+}
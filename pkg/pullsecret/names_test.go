@@ -0,0 +1,45 @@
+package pullsecret
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("my.secret"))
+	assert.NoError(t, ValidateName(strings.Repeat("a", MaxNameLength)))
+
+	tests := map[string]string{
+		"":                                   "must not be empty",
+		strings.Repeat("a", MaxNameLength+1): "must be no more than 253 characters, has 254",
+		"My-Secret":                          "must consist of lowercase characters",
+		"my_secret":                          `contains '_', only lowercase alphanumeric characters, '-' and '.' are allowed`,
+		"-my-secret":                         "must start and end with an alphanumeric character",
+		"my.-secret":                         "every dot separated part must start and end with an alphanumeric character",
+		"my..secret":                         "every dot separated part must start and end with an alphanumeric character",
+	}
+	for name, reason := range tests {
+		err := ValidateName(name)
+		assert.ErrorIs(t, err, ErrInvalid, name)
+		assert.EqualError(t, err, "name is invalid: "+reason, name)
+	}
+}
+
+func TestValidateNamespace(t *testing.T) {
+	assert.NoError(t, ValidateNamespace("team-a"))
+	assert.NoError(t, ValidateNamespace(strings.Repeat("n", MaxNamespaceLength)))
+
+	tests := map[string]string{
+		"my.namespace":           "must not contain '.'",
+		strings.Repeat("n", 100): "must be no more than 63 characters, has 100",
+		"team a":                 `contains ' ', only lowercase alphanumeric characters and '-' are allowed`,
+		"team-":                  "must start and end with an alphanumeric character",
+		"Team":                   "must consist of lowercase characters",
+	}
+	for namespace, reason := range tests {
+		err := ValidateNamespace(namespace)
+		assert.EqualError(t, err, "namespace is invalid: "+reason, namespace)
+	}
+}
@@ -0,0 +1,308 @@
+package pullsecret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyVersion is the version of the policy files read by ParsePolicy
+const PolicyVersion = 1
+
+// Rules of a Policy, used as Violation.Rule and as keys of Policy.Severity
+const (
+	RuleForbiddenNamespaces = "forbiddenNamespaces"
+	RuleRequireNamespace    = "requireNamespace"
+	RuleNamePattern         = "namePattern"
+	RuleRequiredLabels      = "requiredLabels"
+	RuleAllowedRegistries   = "allowedRegistries"
+)
+
+// PolicyRules are all rules a policy can contain
+var PolicyRules = []string{
+	RuleForbiddenNamespaces,
+	RuleRequireNamespace,
+	RuleNamePattern,
+	RuleRequiredLabels,
+	RuleAllowedRegistries,
+}
+
+// Severity tells whether a violation blocks the secret or is only reported
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Policy are the rules of an organization for generated pull secrets, usually read from a YAML file:
+//
+//	version: 1
+//	forbiddenNamespaces: [default, kube-system]
+//	requireNamespace: true
+//	namePattern: ^pull-[a-z0-9-]+$
+//	requiredLabels: [owner]
+//	allowedRegistries: [ghcr.io, "*.dkr.ecr.*.amazonaws.com"]
+//	severity:
+//	  requiredLabels: warning
+//
+// Every violated rule is an error unless its severity is set to warning. Empty rules are not checked.
+type Policy struct {
+	Version int `yaml:"version"`
+	// namespaces no secret may be created in
+	ForbiddenNamespaces []string `yaml:"forbiddenNamespaces,omitempty"`
+	// secrets without namespace land in the namespace of the current kubectl context
+	RequireNamespace bool `yaml:"requireNamespace,omitempty"`
+	// regular expression the secret name must match
+	NamePattern string `yaml:"namePattern,omitempty"`
+	// label keys every secret needs with a non-empty value
+	RequiredLabels []string `yaml:"requiredLabels,omitempty"`
+	// registry hosts, patterns like *.example.com are matched with path.Match
+	AllowedRegistries []string `yaml:"allowedRegistries,omitempty"`
+	// severity per rule, rules not listed are errors
+	Severity map[string]Severity `yaml:"severity,omitempty"`
+
+	nameRegex *regexp.Regexp
+}
+
+// Violation is one broken rule of a policy
+type Violation struct {
+	// one of the Rule constants
+	Rule     string
+	Severity Severity
+	// one of the Field constants
+	Field string
+	// the rejected value
+	Value string
+	// human readable explanation
+	Reason string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s %v: %s", v.Field, ErrPolicy, v.Reason)
+}
+
+func (v Violation) Unwrap() error {
+	return ErrPolicy
+}
+
+// Violations are the broken rules of one or more checks
+type Violations []Violation
+
+// Errors returns the violations which block the secret
+func (vs Violations) Errors() Violations {
+	return vs.bySeverity(SeverityError)
+}
+
+// Warnings returns the violations which are only reported
+func (vs Violations) Warnings() Violations {
+	return vs.bySeverity(SeverityWarning)
+}
+
+// Field returns the violations of one of the Field constants
+func (vs Violations) Field(field string) Violations {
+	var filtered Violations
+	for _, v := range vs {
+		if v.Field == field {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// Err returns the errors joined, nil if there are only warnings
+func (vs Violations) Err() error {
+	var errs []error
+	for _, v := range vs.Errors() {
+		errs = append(errs, v)
+	}
+	return errors.Join(errs...)
+}
+
+func (vs Violations) bySeverity(severity Severity) Violations {
+	var filtered Violations
+	for _, v := range vs {
+		if v.Severity == severity {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// ParsePolicy reads a policy file, unknown keys are rejected to catch typos
+func ParsePolicy(data []byte) (*Policy, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the policy is empty")
+		}
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	switch {
+	case p.Version == 0:
+		return nil, errors.New("invalid policy: version is missing")
+	case p.Version > PolicyVersion:
+		return nil, fmt.Errorf("the policy has version %d, this version reads up to version %d", p.Version, PolicyVersion)
+	}
+
+	if err := p.Compile(); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return &p, nil
+}
+
+// LoadPolicy reads and parses a policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Compile checks the rules of a policy built in code, ParsePolicy calls it for policy files
+func (p *Policy) Compile() error {
+	for _, namespace := range p.ForbiddenNamespaces {
+		if err := ValidateNamespace(namespace); err != nil {
+			return fmt.Errorf("%s: %w", RuleForbiddenNamespaces, err)
+		}
+	}
+	for _, key := range p.RequiredLabels {
+		if reason := checkLabelKey(key); reason != "" {
+			return fmt.Errorf("%s: label key %q %s", RuleRequiredLabels, key, reason)
+		}
+	}
+	for _, pattern := range p.AllowedRegistries {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("%s: invalid pattern %q", RuleAllowedRegistries, pattern)
+		}
+	}
+	for _, rule := range slices.Sorted(maps.Keys(p.Severity)) {
+		if !slices.Contains(PolicyRules, rule) {
+			return fmt.Errorf("severity: unknown rule %q", rule)
+		}
+		if severity := p.Severity[rule]; severity != SeverityError && severity != SeverityWarning {
+			return fmt.Errorf("severity of %s: expected %s or %s, found %q", rule, SeverityError, SeverityWarning, severity)
+		}
+	}
+
+	p.nameRegex = nil
+	if p.NamePattern != "" {
+		nameRegex, err := regexp.Compile(p.NamePattern)
+		if err != nil {
+			return fmt.Errorf("%s: %w", RuleNamePattern, err)
+		}
+		p.nameRegex = nameRegex
+	}
+	return nil
+}
+
+// Check returns the violations of a secret: its name, namespace, labels and every registry of its
+// docker config. A nil policy allows everything.
+func (p *Policy) Check(s *Secret) Violations {
+	if p == nil {
+		return nil
+	}
+
+	violations := p.CheckName(s.Metadata.Name)
+	violations = append(violations, p.CheckNamespace(s.Metadata.Namespace)...)
+	violations = append(violations, p.CheckLabels(s.Metadata.Labels)...)
+
+	// an unreadable docker config is reported by Validate
+	if cfg, err := s.DockerConfig(); err == nil {
+		for _, registry := range slices.Sorted(maps.Keys(cfg.Auths)) {
+			violations = append(violations, p.CheckRegistry(registry)...)
+		}
+	}
+	return violations
+}
+
+// CheckName returns the violations of a secret name
+func (p *Policy) CheckName(name string) Violations {
+	if p == nil || p.NamePattern == "" {
+		return nil
+	}
+
+	nameRegex := p.nameRegex
+	if nameRegex == nil {
+		// policy built in code without Compile
+		var err error
+		if nameRegex, err = regexp.Compile(p.NamePattern); err != nil {
+			return Violations{p.violation(RuleNamePattern, FieldName, name, err.Error())}
+		}
+	}
+	if nameRegex.MatchString(name) {
+		return nil
+	}
+	return Violations{p.violation(RuleNamePattern, FieldName, name, fmt.Sprintf("%q does not match %s", name, p.NamePattern))}
+}
+
+// CheckNamespace returns the violations of a namespace, an empty namespace stands for none
+func (p *Policy) CheckNamespace(namespace string) Violations {
+	switch {
+	case p == nil:
+		return nil
+	case namespace == "" && p.RequireNamespace:
+		return Violations{p.violation(RuleRequireNamespace, FieldNamespace, namespace, "a namespace is required")}
+	case namespace != "" && slices.Contains(p.ForbiddenNamespaces, namespace):
+		return Violations{p.violation(RuleForbiddenNamespaces, FieldNamespace, namespace, fmt.Sprintf("no pull secrets in namespace %s", namespace))}
+	}
+	return nil
+}
+
+// CheckLabels returns one violation per missing or empty required label
+func (p *Policy) CheckLabels(labels map[string]string) Violations {
+	if p == nil {
+		return nil
+	}
+
+	var violations Violations
+	for _, key := range p.RequiredLabels {
+		if strings.TrimSpace(labels[key]) == "" {
+			violations = append(violations, p.violation(RuleRequiredLabels, FieldLabels, key, fmt.Sprintf("label %s is required", key)))
+		}
+	}
+	return violations
+}
+
+// CheckRegistry returns the violations of a registry, only its host is compared, see RegistryHost
+func (p *Policy) CheckRegistry(registry string) Violations {
+	if p == nil || len(p.AllowedRegistries) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(RegistryHost(registry))
+	for _, pattern := range p.AllowedRegistries {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return nil
+		}
+	}
+	return Violations{p.violation(RuleAllowedRegistries, FieldRegistry, registry,
+		fmt.Sprintf("%s is not one of the allowed registries %s", host, strings.Join(p.AllowedRegistries, ", ")))}
+}
+
+func (p *Policy) violation(rule, field, value, reason string) Violation {
+	severity := p.Severity[rule]
+	if severity == "" {
+		severity = SeverityError
+	}
+	return Violation{Rule: rule, Severity: severity, Field: field, Value: value, Reason: reason}
+}
@@ -0,0 +1,102 @@
+package pullsecret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicy = `version: 1
+forbiddenNamespaces: [default, kube-system]
+requireNamespace: true
+namePattern: ^pull-[a-z0-9-]+$
+requiredLabels: [owner]
+allowedRegistries: [ghcr.io, "*.dkr.ecr.*.amazonaws.com"]
+severity:
+  requiredLabels: warning
+`
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "kube-system"}, p.ForbiddenNamespaces)
+	assert.Equal(t, SeverityWarning, p.Severity[RuleRequiredLabels])
+
+	tests := map[string]string{
+		"":                                          "the policy is empty",
+		"namePattern: ^pull-":                       "invalid policy: version is missing",
+		"version: 2":                                "the policy has version 2, this version reads up to version 1",
+		"version: 1\nnamePatern: x":                 "invalid policy: yaml: unmarshal errors:\n  line 2: field namePatern not found in type pullsecret.Policy",
+		"version: 1\nnamePattern: '(['":             "invalid policy: namePattern: error parsing regexp: missing closing ]: `[`",
+		"version: 1\nforbiddenNamespaces: [A]":      "invalid policy: forbiddenNamespaces: namespace is invalid: must consist of lowercase characters",
+		"version: 1\nallowedRegistries: ['[']":      `invalid policy: allowedRegistries: invalid pattern "["`,
+		"version: 1\nseverity: {names: error}":      `invalid policy: severity: unknown rule "names"`,
+		"version: 1\nseverity: {namePattern: info}": `invalid policy: severity of namePattern: expected error or warning, found "info"`,
+	}
+	for content, msg := range tests {
+		_, err := ParsePolicy([]byte(content))
+		assert.EqualError(t, err, msg, content)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+
+	p, err := LoadPolicy(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "^pull-[a-z0-9-]+$", p.NamePattern)
+
+	_, err = LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestPolicy_Check(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	assert.NoError(t, err)
+
+	allowed, err := New("https://ghcr.io/v2/", "ci", "token", WithName("pull-ghcr"), WithNamespace("apps"),
+		WithLabels(map[string]string{"owner": "team-a"}))
+	assert.NoError(t, err)
+	assert.Empty(t, p.Check(allowed))
+
+	ecr, err := New("123.dkr.ecr.eu-west-1.amazonaws.com", "AWS", "token", WithName("pull-ecr"), WithNamespace("apps"),
+		WithLabels(map[string]string{"owner": "team-a"}))
+	assert.NoError(t, err)
+	assert.Empty(t, p.Check(ecr))
+
+	denied, err := New("docker.io", "ci", "token", WithName("ghcr"), WithNamespace("kube-system"))
+	assert.NoError(t, err)
+	violations := p.Check(denied)
+	assert.Len(t, violations, 4)
+	assert.Equal(t, []string{FieldName, FieldNamespace, FieldRegistry}, fields(violations.Errors()))
+	assert.Equal(t, Violations{{
+		Rule: RuleRequiredLabels, Severity: SeverityWarning, Field: FieldLabels, Value: "owner", Reason: "label owner is required",
+	}}, violations.Warnings())
+	assert.ErrorIs(t, violations.Err(), ErrPolicy)
+	assert.EqualError(t, violations.Field(FieldNamespace).Err(), "namespace is not allowed by the policy: no pull secrets in namespace kube-system")
+
+	assert.Len(t, p.CheckNamespace(""), 1)
+	assert.NoError(t, violations.Warnings().Err())
+
+	// a nil policy allows everything
+	var none *Policy
+	assert.Empty(t, none.Check(denied))
+	assert.Empty(t, none.CheckNamespace("default"))
+}
+
+func TestPolicy_CheckName_WithoutCompile(t *testing.T) {
+	p := &Policy{NamePattern: "^pull-"}
+	assert.Empty(t, p.CheckName("pull-a"))
+	assert.Len(t, p.CheckName("a"), 1)
+}
+
+func fields(violations Violations) []string {
+	var result []string
+	for _, v := range violations {
+		result = append(result, v.Field)
+	}
+	return result
+}
@@ -0,0 +1,200 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// dockerHubHosts are the registry hosts which all mean Docker Hub
+var dockerHubHosts = []string{"docker.io", "index.docker.io", "registry-1.docker.io"}
+
+// RotateChange is one auths entry whose password was replaced by Rotate
+type RotateChange struct {
+	// 1-based position of the YAML document in the file
+	Document  int
+	Name      string
+	Namespace string
+	// key of the auths entry, e.g. "https://index.docker.io/v1/" for "docker.io"
+	Registry string
+	Username string
+	// the entry had the new password already. Neither password is kept, not even as a hash, so
+	// reports can end up in CI logs.
+	Unchanged bool
+}
+
+func (c RotateChange) String() string {
+	location := fmt.Sprintf("document %d", c.Document)
+	if c.Name != "" {
+		location += " (" + c.Name
+		if c.Namespace != "" {
+			location += " in " + c.Namespace
+		}
+		location += ")"
+	}
+
+	if c.Unchanged {
+		return fmt.Sprintf("%s %s %s: password is up to date", location, c.Registry, c.Username)
+	}
+	return fmt.Sprintf("%s %s %s: password changed", location, c.Registry, c.Username)
+}
+
+// RotateResult are the rotated entries of a manifest file
+type RotateResult struct {
+	Changes []RotateChange
+	// number of secrets in the file, documents of other kinds are skipped
+	Secrets int
+	// secrets whose docker config can not be read, they are left as they are. Lint repairs most of them.
+	Skipped []Finding
+
+	manifest *manifest
+}
+
+// Changed reports whether any entry got a new password
+func (r *RotateResult) Changed() bool {
+	return slices.ContainsFunc(r.Changes, func(c RotateChange) bool { return !c.Unchanged })
+}
+
+// Rotated returns the manifest with the new passwords. Documents without rotated entries keep their
+// original text, the rotated ones keep unknown fields, comments and the indentation of the file.
+func (r *RotateResult) Rotated() ([]byte, error) {
+	return r.manifest.encode()
+}
+
+// Rotate replaces the password of every auths entry of the registry and username in all Secrets of a
+// multi-document YAML. Registries are compared by host, so "ghcr.io" matches "https://ghcr.io" and
+// "docker.io" the Docker Hub key. The auth of an entry is updated with its password, names, namespaces,
+// labels and the entries of other registries or users are not touched.
+func Rotate(data []byte, registry, username, password string) (*RotateResult, error) {
+	if err := ValidateCredentials(registry, username, password); err != nil {
+		return nil, err
+	}
+
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RotateResult{manifest: m}
+	for _, doc := range m.docs {
+		if !doc.isSecret() {
+			continue
+		}
+		result.Secrets++
+
+		changes, skipped := rotateDocument(doc, registry, username, password)
+		result.Changes = append(result.Changes, changes...)
+		if skipped != nil {
+			result.Skipped = append(result.Skipped, *skipped)
+		}
+	}
+	return result, nil
+}
+
+// rotateDocument rotates the matching entries of one secret, the docker config is only written again
+// if a password changed
+func rotateDocument(doc *document, registry, username, password string) ([]RotateChange, *Finding) {
+	skip := func(format string, args ...any) *Finding {
+		return &Finding{
+			Document: doc.index,
+			Name:     doc.get("metadata", "name"),
+			Rule:     LintDockerConfig,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	section := "data"
+	value := doc.get("data", DataKey)
+	if value == "" {
+		section = "stringData"
+		value = doc.get("stringData", DataKey)
+	}
+	if value == "" {
+		if doc.get("type") == TypeDockerConfigJSON {
+			return nil, skip("no docker config found, expected %s in data", DataKey)
+		}
+		// e.g. an Opaque secret of the same file
+		return nil, nil
+	}
+
+	plain, encodingProblem, err := decodeConfigValue(section, value)
+	if err == nil && encodingProblem != "" {
+		err = fmt.Errorf("%s %s", DataKey, encodingProblem)
+	}
+	var cfg *rawDockerConfig
+	if err == nil {
+		cfg, err = parseRawDockerConfig(plain)
+	}
+	if err != nil {
+		return nil, skip("%s %s: %v", section, DataKey, err)
+	}
+
+	var changes []RotateChange
+	for _, key := range cfg.registries() {
+		if !sameRegistry(key, registry) {
+			continue
+		}
+
+		entryUser, oldPassword := cfg.entry(key, "username"), cfg.entry(key, "password")
+		_, hasPassword := cfg.auths[key]["password"]
+		_, hasAuth := cfg.auths[key]["auth"]
+		if decoded, err := base64.StdEncoding.DecodeString(cfg.entry(key, "auth")); err == nil && (entryUser == "" || !hasPassword) {
+			authUser, authPassword, _ := strings.Cut(string(decoded), ":")
+			if entryUser == "" {
+				entryUser = authUser
+			}
+			if !hasPassword {
+				oldPassword = authPassword
+			}
+		}
+		if entryUser != username {
+			continue
+		}
+
+		changes = append(changes, RotateChange{
+			Document:  doc.index,
+			Name:      doc.get("metadata", "name"),
+			Namespace: doc.get("metadata", "namespace"),
+			Registry:  key,
+			Username:  username,
+			Unchanged: oldPassword == password,
+		})
+		if oldPassword == password {
+			continue
+		}
+
+		// keep the fields the entry has, entries with neither get both
+		if hasPassword || !hasAuth {
+			cfg.setEntry(key, "password", password)
+		}
+		if hasAuth || !hasPassword {
+			cfg.setEntry(key, "auth", base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+		}
+	}
+
+	if !slices.ContainsFunc(changes, func(c RotateChange) bool { return !c.Unchanged }) {
+		return changes, nil
+	}
+
+	rotated, err := cfg.marshal()
+	if err != nil {
+		return nil, skip("%v", err)
+	}
+	if section == "stringData" {
+		doc.set(string(rotated), "stringData", DataKey)
+	} else {
+		doc.set(base64.StdEncoding.EncodeToString(rotated), "data", DataKey)
+	}
+	return changes, nil
+}
+
+// sameRegistry compares two registries by their host, the Docker Hub hosts are all the same
+func sameRegistry(a, b string) bool {
+	hostA, hostB := strings.ToLower(RegistryHost(a)), strings.ToLower(RegistryHost(b))
+	if hostA == hostB {
+		return true
+	}
+	return slices.Contains(dockerHubHosts, hostA) && slices.Contains(dockerHubHosts, hostB)
+}
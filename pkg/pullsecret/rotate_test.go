@@ -0,0 +1,174 @@
+package pullsecret
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotate(t *testing.T) {
+	cfg := `{"auths":{"https://ghcr.io":{"username":"ci","password":"old","auth":"` + b64("ci:old") + `"},"quay.io":{"auth":"` + b64("ci:old") + `"}},"credHelpers":{"gcr.io":"gcloud"}}`
+	manifest := `# rotated by the deploy pipeline
+apiVersion: v1
+kind: Secret
+metadata:
+  name: ghcr-pull
+  namespace: team-a
+  labels:
+    owner: team-a
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: ` + b64(cfg) + `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: other-user
+type: kubernetes.io/dockerconfigjson
+stringData:
+  .dockerconfigjson: '{"auths":{"ghcr.io":{"auth":"` + b64("bot:old") + `"}}}'
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: broken
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: not-base64
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+`
+
+	result, err := Rotate([]byte(manifest), "ghcr.io", "ci", "new")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Secrets)
+	assert.True(t, result.Changed())
+	if assert.Len(t, result.Changes, 1) {
+		c := result.Changes[0]
+		assert.Equal(t, "https://ghcr.io", c.Registry)
+		assert.False(t, c.Unchanged)
+		assert.Equal(t, "document 1 (ghcr-pull in team-a) https://ghcr.io ci: password changed", c.String())
+		assert.NotContains(t, c.String(), "old")
+	}
+	if assert.Len(t, result.Skipped, 1) {
+		assert.Equal(t, "broken", result.Skipped[0].Name)
+	}
+
+	rotated, err := result.Rotated()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rotated), "# rotated by the deploy pipeline")
+	assert.Contains(t, string(rotated), "owner: team-a")
+	assert.Contains(t, string(rotated), "kind: ConfigMap")
+	assert.Contains(t, string(rotated), b64("bot:old"), "other users are kept")
+
+	secrets, err := ParseAll(rotated)
+	assert.NoError(t, err)
+	plain, err := secrets[0].Decoded()
+	assert.NoError(t, err)
+	raw, err := parseRawDockerConfig([]byte(plain.Data[DataKey]))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", raw.entry("https://ghcr.io", "password"))
+	assert.Equal(t, b64("ci:new"), raw.entry("https://ghcr.io", "auth"))
+	assert.Equal(t, b64("ci:old"), raw.entry("quay.io", "auth"), "other registries are kept")
+	assert.Contains(t, raw.fields, "credHelpers")
+
+	// a second run finds the new password
+	again, err := Rotate(rotated, "ghcr.io", "ci", "new")
+	assert.NoError(t, err)
+	assert.False(t, again.Changed())
+	if assert.Len(t, again.Changes, 1) {
+		assert.True(t, again.Changes[0].Unchanged)
+	}
+}
+
+func TestRotate_AuthOnly(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Secret
+metadata:
+  name: hub-pull
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: ` + b64(`{"auths":{"`+DockerHubLegacyKey+`":{"auth":"`+b64("ci:old")+`"}}}`) + `
+`
+
+	result, err := Rotate([]byte(manifest), "docker.io", "ci", "new")
+	assert.NoError(t, err)
+	assert.Len(t, result.Changes, 1)
+
+	rotated, err := result.Rotated()
+	assert.NoError(t, err)
+	secret, err := Parse(rotated)
+	assert.NoError(t, err)
+	plain, err := secret.Decoded()
+	assert.NoError(t, err)
+	raw, err := parseRawDockerConfig([]byte(plain.Data[DataKey]))
+	assert.NoError(t, err)
+	assert.Equal(t, b64("ci:new"), raw.entry(DockerHubLegacyKey, "auth"))
+	assert.NotContains(t, raw.auths[DockerHubLegacyKey], "password", "the entry keeps its fields")
+}
+
+func TestRotate_Invalid(t *testing.T) {
+	_, err := Rotate(nil, "ghcr.io", "ci", "")
+	assert.ErrorIs(t, err, ErrRequired)
+
+	_, err = Rotate([]byte("a: [b"), "ghcr.io", "ci", "new")
+	assert.Error(t, err)
+}
+
+func TestRotate_KeepsOtherDocuments(t *testing.T) {
+	deployment := `# the app
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: app
+spec:
+    template:
+        spec:
+            imagePullSecrets:
+                - name: ghcr-pull
+            containers:
+                - name: app
+                  image: "ghcr.io/team/app:1.0"
+`
+	secret := `---
+apiVersion: v1
+kind: Secret
+metadata:
+    name: ghcr-pull
+type: kubernetes.io/dockerconfigjson
+data:
+    .dockerconfigjson: ` + b64(`{"auths":{"ghcr.io":{"username":"ci","password":"old"}}}`) + `
+`
+	other := `--- # quay
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quay-pull
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: ` + b64(`{"auths":{"quay.io":{"username":"ci","password":"old"}}}`) + `
+`
+
+	result, err := Rotate([]byte(deployment+secret+other), "ghcr.io", "ci", "new")
+	assert.NoError(t, err)
+	assert.True(t, result.Changed())
+
+	rotated, err := result.Rotated()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(rotated), deployment), "documents before are byte-identical")
+	assert.True(t, strings.HasSuffix(string(rotated), other), "documents after are byte-identical")
+	rotatedSecret := strings.TrimSuffix(strings.TrimPrefix(string(rotated), deployment), other)
+	assert.Contains(t, rotatedSecret, "---\napiVersion: v1\n")
+	assert.Contains(t, rotatedSecret, "\nmetadata:\n    name: ghcr-pull\n", "the indentation of the file is kept")
+	assert.NotContains(t, rotatedSecret, b64(`{"auths":{"ghcr.io":{"username":"ci","password":"old"}}}`))
+
+	unchanged, err := Rotate([]byte(deployment+secret+other), "ghcr.io", "bot", "new")
+	assert.NoError(t, err)
+	same, err := unchanged.Rotated()
+	assert.NoError(t, err)
+	assert.Equal(t, deployment+secret+other, string(same))
+}
@@ -0,0 +1,292 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+const (
+	APIVersion           = "v1"
+	Kind                 = "Secret"
+	TypeDockerConfigJSON = "kubernetes.io/dockerconfigjson"
+	DataKey              = ".dockerconfigjson"
+)
+
+var placeholderRegex = regexp.MustCompile(`<[^>]*>`)
+
+// DockerConfig is the content of the .dockerconfigjson key
+type DockerConfig struct {
+	Auths map[string]AuthEntry `json:"auths"`
+}
+
+// AuthEntry holds the credentials of one registry, Auth is base64 of "username:password"
+type AuthEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// Secret is a Kubernetes secret manifest
+type Secret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   Metadata          `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+}
+
+type Metadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// Option configures a secret created by New
+type Option func(*Secret)
+
+// WithName sets the secret name, a random name is generated if it is not set or empty
+func WithName(name string) Option {
+	return func(s *Secret) {
+		s.Metadata.Name = name
+	}
+}
+
+// WithNamespace sets the namespace, the secret has no namespace if it is not set or empty
+func WithNamespace(namespace string) Option {
+	return func(s *Secret) {
+		s.Metadata.Namespace = namespace
+	}
+}
+
+// WithLabels sets the labels of the secret
+func WithLabels(labels map[string]string) Option {
+	return func(s *Secret) {
+		s.Metadata.Labels = labels
+	}
+}
+
+// New creates an image pull secret for one registry. All inputs are validated,
+// the first invalid one is returned as *ValidationError.
+func New(registry, username, password string, opts ...Option) (*Secret, error) {
+	secret := &Secret{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Type:       TypeDockerConfigJSON,
+	}
+	for _, opt := range opts {
+		opt(secret)
+	}
+
+	if secret.Metadata.Name == "" {
+		secret.Metadata.Name = GenerateName()
+	}
+
+	if err := ValidateCredentials(registry, username, password); err != nil {
+		return nil, err
+	}
+	if err := validateMetadata(secret.Metadata); err != nil {
+		return nil, err
+	}
+
+	data, err := EncodeDockerConfig(NewDockerConfig(registry, username, password))
+	if err != nil {
+		return nil, err
+	}
+	secret.Data = map[string]string{DataKey: data}
+
+	return secret, nil
+}
+
+// HasPlaceholder reports whether the value still contains a placeholder like <region>
+func HasPlaceholder(value string) bool {
+	return placeholderRegex.MatchString(value)
+}
+
+// ValidateCredentials checks the registry, username and password of a pull secret
+func ValidateCredentials(registry, username, password string) error {
+	switch {
+	case registry == "":
+		return newValidationError(FieldRegistry, registry, ErrRequired, "")
+	case HasPlaceholder(registry):
+		return newValidationError(FieldRegistry, registry, ErrPlaceholder, "replace the placeholder in the registry host")
+	case strings.ContainsAny(registry, " \t\r\n"):
+		return newValidationError(FieldRegistry, registry, ErrInvalid, "the registry host must not contain whitespace")
+	case username == "":
+		return newValidationError(FieldUsername, username, ErrRequired, "")
+	case strings.Contains(username, ":"):
+		// the auth field is "username:password", a colon in the username can not be split again
+		return newValidationError(FieldUsername, username, ErrInvalid, "the username must not contain ':'")
+	case password == "":
+		return newValidationError(FieldPassword, "", ErrRequired, "")
+	}
+	return nil
+}
+
+func validateMetadata(metadata Metadata) error {
+	if metadata.Name == "" {
+		return newValidationError(FieldName, "", ErrRequired, "")
+	}
+	if err := ValidateName(metadata.Name); err != nil {
+		return err
+	}
+	if metadata.Namespace != "" {
+		if err := ValidateNamespace(metadata.Namespace); err != nil {
+			return err
+		}
+	}
+	return ValidateLabels(metadata.Labels)
+}
+
+// NewDockerConfig returns the docker config with the credentials of one registry
+func NewDockerConfig(registry, username, password string) *DockerConfig {
+	return &DockerConfig{
+		Auths: map[string]AuthEntry{
+			registry: {
+				Username: username,
+				Password: password,
+				Auth:     base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "%s:%s", username, password)),
+			},
+		},
+	}
+}
+
+// EncodeDockerConfig returns the docker config as base64 encoded JSON, as stored in the secret data
+func EncodeDockerConfig(cfg *DockerConfig) (string, error) {
+	jsonBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(jsonBytes), nil
+}
+
+// DecodeDockerConfig parses the base64 encoded JSON of the secret data
+func DecodeDockerConfig(data string) (*DockerConfig, error) {
+	jsonBytes, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, newValidationError(FieldDockerConfig, "", ErrInvalid, "no valid base64: "+err.Error())
+	}
+
+	var cfg DockerConfig
+	if err := json.Unmarshal(jsonBytes, &cfg); err != nil {
+		return nil, newValidationError(FieldDockerConfig, "", ErrInvalid, "no valid JSON: "+err.Error())
+	}
+	return &cfg, nil
+}
+
+// DockerConfig returns the decoded docker config of the secret, read from data or stringData
+func (s *Secret) DockerConfig() (*DockerConfig, error) {
+	if plain, ok := s.StringData[DataKey]; ok {
+		var cfg DockerConfig
+		if err := json.Unmarshal([]byte(plain), &cfg); err != nil {
+			return nil, newValidationError(FieldDockerConfig, "", ErrInvalid, "no valid JSON: "+err.Error())
+		}
+		return &cfg, nil
+	}
+
+	data, ok := s.Data[DataKey]
+	if !ok {
+		return nil, newValidationError(FieldDockerConfig, "", ErrRequired, "the secret has no "+DataKey+" data")
+	}
+	return DecodeDockerConfig(data)
+}
+
+// Credentials returns the registry and auth entry of a secret with exactly one registry
+func (s *Secret) Credentials() (string, AuthEntry, error) {
+	cfg, err := s.DockerConfig()
+	if err != nil {
+		return "", AuthEntry{}, err
+	}
+
+	if len(cfg.Auths) != 1 {
+		return "", AuthEntry{}, fmt.Errorf("expected exactly one registry in docker config, found %d", len(cfg.Auths))
+	}
+
+	for registry, entry := range cfg.Auths {
+		return registry, entry, nil
+	}
+
+	return "", AuthEntry{}, nil
+}
+
+// Decoded returns a copy of the secret with all data values, the docker config from data or stringData
+// included, as plain text in data instead of base64. It is handy to inspect the credentials but no valid
+// manifest. An error is returned if the secret has no valid docker config.
+func (s *Secret) Decoded() (*Secret, error) {
+	plain, err := s.WithStringData()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := plain.DockerConfig(); err != nil {
+		return nil, err
+	}
+
+	return &Secret{
+		APIVersion: s.APIVersion,
+		Kind:       s.Kind,
+		Type:       s.Type,
+		Metadata:   s.Metadata,
+		Data:       plain.StringData,
+	}, nil
+}
+
+// WithStringData returns a copy of the secret with all data values decoded to stringData,
+// Kubernetes encodes them again when the manifest is applied
+func (s *Secret) WithStringData() (*Secret, error) {
+	stringData := make(map[string]string, len(s.Data)+len(s.StringData))
+	for key, value := range s.Data {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("data %s: %w", key, err)
+		}
+		stringData[key] = string(decoded)
+	}
+	// stringData wins over data on apply
+	maps.Copy(stringData, s.StringData)
+
+	copied := *s
+	copied.Data = nil
+	copied.StringData = stringData
+	return &copied, nil
+}
+
+// Validate checks a parsed secret: kind, type, metadata and the docker config with its credentials.
+// All problems are returned joined, each one as *ValidationError.
+func (s *Secret) Validate() error {
+	var errs []error
+
+	if s.APIVersion != APIVersion || s.Kind != Kind {
+		errs = append(errs, newValidationError(FieldType, s.APIVersion+"/"+s.Kind, ErrInvalid, "expected "+APIVersion+"/"+Kind))
+	}
+	if s.Type != TypeDockerConfigJSON {
+		errs = append(errs, newValidationError(FieldType, s.Type, ErrInvalid, "expected "+TypeDockerConfigJSON))
+	}
+	if err := validateMetadata(s.Metadata); err != nil {
+		errs = append(errs, err)
+	}
+
+	cfg, err := s.DockerConfig()
+	if err != nil {
+		errs = append(errs, err)
+	} else if len(cfg.Auths) == 0 {
+		errs = append(errs, newValidationError(FieldDockerConfig, "", ErrRequired, "the docker config has no registry"))
+	} else {
+		for registry, entry := range cfg.Auths {
+			username, password := entry.Username, entry.Password
+			if decoded, err := base64.StdEncoding.DecodeString(entry.Auth); err == nil && username == "" && password == "" {
+				// entries with only auth are valid for kubelet, containerd and docker
+				username, password, _ = strings.Cut(string(decoded), ":")
+			}
+			if err := ValidateCredentials(registry, username, password); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
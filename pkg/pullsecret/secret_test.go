@@ -0,0 +1,118 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	secret, err := New("ghcr.io", "ci-bot", "token",
+		WithName("ghcr-pull"),
+		WithNamespace("apps"),
+		WithLabels(map[string]string{"team": "a"}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, APIVersion, secret.APIVersion)
+	assert.Equal(t, Kind, secret.Kind)
+	assert.Equal(t, TypeDockerConfigJSON, secret.Type)
+	assert.Equal(t, Metadata{Name: "ghcr-pull", Namespace: "apps", Labels: map[string]string{"team": "a"}}, secret.Metadata)
+
+	registry, auth, err := secret.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "ghcr.io", registry)
+	assert.Equal(t, "ci-bot", auth.Username)
+	assert.Equal(t, "token", auth.Password)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("ci-bot:token")), auth.Auth)
+}
+
+func TestNew_GeneratedName(t *testing.T) {
+	secret, err := New("ghcr.io", "ci-bot", "token")
+	assert.NoError(t, err)
+	assert.True(t, IsValidName(secret.Metadata.Name))
+	assert.Empty(t, secret.Metadata.Namespace)
+}
+
+func TestNew_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		registry, username, password string
+		opts                         []Option
+		field                        string
+		sentinel                     error
+	}{
+		{"", "user", "pass", nil, FieldRegistry, ErrRequired},
+		{"<region>-docker.pkg.dev", "user", "pass", nil, FieldRegistry, ErrPlaceholder},
+		{"my registry", "user", "pass", nil, FieldRegistry, ErrInvalid},
+		{"ghcr.io", "", "pass", nil, FieldUsername, ErrRequired},
+		{"ghcr.io", "us:er", "pass", nil, FieldUsername, ErrInvalid},
+		{"ghcr.io", "user", "", nil, FieldPassword, ErrRequired},
+		{"ghcr.io", "user", "pass", []Option{WithName("Invalid_Name")}, FieldName, ErrInvalid},
+		{"ghcr.io", "user", "pass", []Option{WithNamespace("-apps")}, FieldNamespace, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		_, err := New(tt.registry, tt.username, tt.password, tt.opts...)
+
+		var verr *ValidationError
+		if assert.True(t, errors.As(err, &verr), "%v", err) {
+			assert.Equal(t, tt.field, verr.Field)
+		}
+		assert.ErrorIs(t, err, tt.sentinel)
+	}
+}
+
+func TestValidationError_PasswordNotIncluded(t *testing.T) {
+	err := ValidateCredentials("ghcr.io", "user", "")
+	assert.EqualError(t, err, "password is required")
+}
+
+func TestDecoded(t *testing.T) {
+	secret, _ := New("ghcr.io", "user", "pass", WithName("ghcr-pull"))
+
+	decoded, err := secret.Decoded()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths":{"ghcr.io":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`, decoded.Data[DataKey])
+	assert.Equal(t, secret.Metadata, decoded.Metadata)
+
+	// the docker config of stringData and further data keys are decoded as well
+	withStringData, err := secret.WithStringData()
+	assert.NoError(t, err)
+	withStringData.StringData["note"] = "pull only"
+	decoded, err = withStringData.Decoded()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths":{"ghcr.io":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`, decoded.Data[DataKey])
+	assert.Equal(t, "pull only", decoded.Data["note"])
+
+	_, err = (&Secret{APIVersion: APIVersion, Kind: Kind, Data: map[string]string{"other": "dGV4dA=="}}).Decoded()
+	assert.ErrorIs(t, err, ErrRequired)
+}
+
+func TestValidate(t *testing.T) {
+	secret, _ := New("ghcr.io", "user", "pass", WithName("ghcr-pull"))
+	assert.NoError(t, secret.Validate())
+
+	broken := &Secret{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Type:       "Opaque",
+		Metadata:   Metadata{Name: "Invalid_Name"},
+		Data:       map[string]string{DataKey: "not base64!"},
+	}
+	err := broken.Validate()
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	// all problems are reported
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var verr *ValidationError
+		if errors.As(e, &verr) {
+			fields = append(fields, verr.Field)
+		}
+	}
+	assert.Equal(t, []string{FieldType, FieldName, FieldDockerConfig}, fields)
+
+	_, err = (&Secret{}).DockerConfig()
+	assert.ErrorIs(t, err, ErrRequired)
+}
@@ -0,0 +1,92 @@
+package pullsecret
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// NameTemplatePlaceholders lists the placeholders of name templates with a short description
+var NameTemplatePlaceholders = []string{
+	"{{.RegistryHost}} registry host without scheme and path",
+	"{{.Namespace}} namespace",
+	"{{.Username}} username",
+	"{{.Date}} current date as YYYYMMDD",
+	"{{.Random}} random 4 character hex suffix",
+}
+
+var (
+	invalidNameCharsRegex = regexp.MustCompile(`[^a-z0-9.-]+`)
+	dashesRegex           = regexp.MustCompile(`-+`)
+	// a dot with surrounding dashes or dots, e.g. "-." or "..", separates two parts
+	dotRunRegex = regexp.MustCompile(`[-.]*\.[-.]*`)
+)
+
+// NameData are the values available in name templates
+type NameData struct {
+	RegistryHost string
+	Namespace    string
+	Username     string
+	Date         string
+	Random       string
+}
+
+// NewNameData returns the template values of a secret
+func NewNameData(registry, username, namespace string, now time.Time) NameData {
+	return NameData{
+		RegistryHost: RegistryHost(registry),
+		Namespace:    namespace,
+		Username:     username,
+		Date:         now.Format("20060102"),
+		Random:       randomSuffix(2),
+	}
+}
+
+// RegistryHost returns the host of a registry without scheme and path, e.g. "index.docker.io" for
+// "https://index.docker.io/v1/"
+func RegistryHost(registry string) string {
+	host := registry
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host, _, _ = strings.Cut(host, "/")
+	return host
+}
+
+// RenderName executes a name template like "{{.RegistryHost}}-pull" and sanitizes the result
+// into a valid secret name
+func RenderName(tmpl string, data NameData) (string, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid name template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid name template: %w", err)
+	}
+
+	name := SanitizeName(b.String())
+	if err := ValidateName(name); err != nil {
+		return "", fmt.Errorf("name template %q: %w", tmpl, err)
+	}
+	return name, nil
+}
+
+// SanitizeName turns any string into a valid secret name as far as possible: lowercase,
+// invalid characters replaced by '-', every dot separated part starting and ending alphanumeric
+// and at most 253 characters. The result is empty if nothing valid remains.
+func SanitizeName(s string) string {
+	name := strings.ToLower(s)
+	name = invalidNameCharsRegex.ReplaceAllString(name, "-")
+	name = dashesRegex.ReplaceAllString(name, "-")
+	name = dotRunRegex.ReplaceAllString(name, ".")
+	name = strings.Trim(name, "-.")
+
+	if len(name) > MaxNameLength {
+		name = strings.TrimRight(name[:MaxNameLength], "-.")
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package pullsecret
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderName(t *testing.T) {
+	data := NewNameData("https://index.docker.io/v1/", "Deploy_Bot", "team-a", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "index.docker.io", data.RegistryHost)
+	assert.Regexp(t, `^[0-9a-f]{4}$`, data.Random)
+
+	name, err := RenderName("{{.RegistryHost}}-pull", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "index.docker.io-pull", name)
+
+	name, err = RenderName("{{.Namespace}}-{{.Username}}-{{.Date}}", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a-deploy-bot-20260301", name)
+
+	name, err = RenderName("pull-{{.Random}}", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "pull-"+data.Random, name)
+
+	_, err = RenderName("{{.Unknown}}", data)
+	assert.Error(t, err)
+
+	_, err = RenderName("{{.RegistryHost", data)
+	assert.Error(t, err)
+
+	_, err = RenderName("{{.Namespace}}", NameData{})
+	assert.ErrorIs(t, err, ErrInvalid, "an empty result is no valid name")
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]string{
+		"localhost:5000-pull":   "localhost-5000-pull",
+		"--My Registry!!--":     "my-registry",
+		"ghcr.io/org":           "ghcr.io-org",
+		"a-.-b..c":              "a.b.c",
+		"___":                   "",
+		"registry.example.com.": "registry.example.com",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, SanitizeName(in), in)
+		if expected != "" {
+			assert.True(t, IsValidName(expected), expected)
+		}
+	}
+
+	long := SanitizeName(strings.Repeat("a", 252) + "-" + strings.Repeat("b", 10))
+	assert.Equal(t, strings.Repeat("a", 252), long, "no trailing dash after truncation")
+}
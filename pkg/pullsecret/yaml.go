@@ -0,0 +1,108 @@
+package pullsecret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentSeparator separates the documents of a multi-document YAML
+const DocumentSeparator = "---\n"
+
+// DefaultIndent is the indentation of the YAML manifests in spaces
+const DefaultIndent = 2
+
+// EncodeOptions configure how a secret is written by EncodeWith
+type EncodeOptions struct {
+	// spaces per indentation level, DefaultIndent if not set
+	Indent int
+	// write the docker config as plain JSON to stringData instead of base64 to data
+	StringData bool
+}
+
+// Encode returns the secret as YAML manifest with an indentation of two spaces
+func (s *Secret) Encode() ([]byte, error) {
+	return s.EncodeWith(EncodeOptions{})
+}
+
+// EncodeWith returns the secret as YAML manifest written with the given options
+func (s *Secret) EncodeWith(opts EncodeOptions) ([]byte, error) {
+	indent := opts.Indent
+	if indent <= 0 {
+		indent = DefaultIndent
+	}
+
+	doc := s
+	if opts.StringData {
+		var err error
+		if doc, err = s.WithStringData(); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeAll returns the secrets as one multi-document YAML
+func EncodeAll(secrets []*Secret) ([]byte, error) {
+	docs := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		doc, err := secret.Encode()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, string(doc))
+	}
+	return []byte(strings.Join(docs, DocumentSeparator)), nil
+}
+
+// Parse reads a secret from a single YAML document
+func Parse(data []byte) (*Secret, error) {
+	secrets, err := ParseAll(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(secrets) != 1 {
+		return nil, fmt.Errorf("expected one YAML document, found %d", len(secrets))
+	}
+	return secrets[0], nil
+}
+
+// ParseAll reads all secrets of a multi-document YAML, empty documents are skipped
+func ParseAll(data []byte) ([]*Secret, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var secrets []*Secret
+	for i := 1; ; i++ {
+		var secret Secret
+		err := dec.Decode(&secret)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if secret.Kind == "" && secret.APIVersion == "" && secret.Metadata.Name == "" {
+			// empty document, e.g. a trailing separator
+			continue
+		}
+		secrets = append(secrets, &secret)
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,69 @@
+package pullsecret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	secret, _ := New("docker.io", "user", "pass", WithName("mysecret"), WithNamespace("apps"))
+
+	doc, err := secret.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: mysecret
+  namespace: apps
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: eyJhdXRocyI6eyJkb2NrZXIuaW8iOnsidXNlcm5hbWUiOiJ1c2VyIiwicGFzc3dvcmQiOiJwYXNzIiwiYXV0aCI6ImRYTmxjanB3WVhOeiJ9fX0=
+`, string(doc))
+
+	parsed, err := Parse(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, parsed)
+}
+
+func TestParseAll(t *testing.T) {
+	first, _ := New("ghcr.io", "user", "pass", WithName("first"))
+	second, _ := New("quay.io", "user", "pass", WithName("second"), WithNamespace("apps"))
+
+	multiDoc, err := EncodeAll([]*Secret{first, second})
+	assert.NoError(t, err)
+
+	secrets, err := ParseAll(append([]byte("---\n"), append(multiDoc, []byte("---\n")...)...))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Secret{first, second}, secrets)
+
+	_, err = Parse(multiDoc)
+	assert.Error(t, err, "Parse expects exactly one document")
+
+	_, err = ParseAll([]byte("kind: [Secret"))
+	assert.Error(t, err)
+}
+
+func TestEncodeWith(t *testing.T) {
+	secret, _ := New("docker.io", "user", "pass", WithName("mysecret"))
+
+	doc, err := secret.EncodeWith(EncodeOptions{Indent: 4, StringData: true})
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+    name: mysecret
+type: kubernetes.io/dockerconfigjson
+stringData:
+    .dockerconfigjson: '{"auths":{"docker.io":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}'
+`, string(doc))
+
+	// the original secret is not changed and the parsed copy holds the same credentials
+	assert.Contains(t, secret.Data, DataKey)
+	parsed, err := Parse(doc)
+	assert.NoError(t, err)
+	assert.NoError(t, parsed.Validate())
+	_, entry, err := parsed.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "pass", entry.Password)
+}
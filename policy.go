@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/javaLux/registrymate/utils"
+)
+
+func (g *generator) buildPolicyHint() {
+	policyHint := widget.NewLabel("")
+	policyHint.Wrapping = fyne.TextWrapWord
+	policyHint.Importance = widget.WarningImportance
+	policyHint.Hide()
+
+	g.policyHint = policyHint
+}
+
+// checkPolicy shows the violated rules of the policy for the entered values, errors at the entries
+// and warnings below the metadata
+func (g *generator) checkPolicy() {
+	for _, entry := range []*widget.Entry{&g.regEntry.Entry, &g.nameEntry.Entry, &g.nameSpaceEntry.Entry, g.labelsEntry} {
+		_ = entry.Validate()
+	}
+
+	warnings := g.inputViolations().Warnings()
+	if len(warnings) == 0 {
+		g.policyHint.SetText("")
+		g.policyHint.Hide()
+		return
+	}
+
+	reasons := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		reasons = append(reasons, "Policy: "+warning.Reason)
+	}
+	g.policyHint.SetText(strings.Join(reasons, "\n"))
+	g.policyHint.Show()
+}
+
+// inputViolations returns the violated rules of the entered values, an empty name is generated later
+func (g *generator) inputViolations() pullsecret.Violations {
+	policy := g.appSettings.Policy()
+	if policy == nil {
+		return nil
+	}
+
+	var violations pullsecret.Violations
+	if registry := strings.TrimSpace(g.regEntry.Text); registry != "" {
+		violations = append(violations, policy.CheckRegistry(registry)...)
+	}
+	if name := strings.TrimSpace(g.nameEntry.Text); name != "" {
+		violations = append(violations, policy.CheckName(name)...)
+	}
+	violations = append(violations, namespaceViolations(policy, utils.ParseNamespaces(g.nameSpaceEntry.Text))...)
+	if labels, err := pullsecret.ParseLabels(g.labelsEntry.Text); err == nil {
+		violations = append(violations, policy.CheckLabels(labels)...)
+	}
+	return violations
+}
+
+// namespaceViolations checks every namespace, no namespaces stand for a secret without namespace
+func namespaceViolations(policy *pullsecret.Policy, namespaces []string) pullsecret.Violations {
+	if len(namespaces) == 0 {
+		return policy.CheckNamespace("")
+	}
+
+	var violations pullsecret.Violations
+	for _, namespace := range namespaces {
+		violations = append(violations, policy.CheckNamespace(namespace)...)
+	}
+	return violations
+}
+
+// firstPolicyError returns the first violation which blocks the secret, shown by the validator of an entry
+func firstPolicyError(violations pullsecret.Violations) error {
+	if errs := violations.Errors(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+func (g *generator) buildPresetSelect() {
+	presets := utils.RegistryPresets()
+	names := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+
+	presetHint := widget.NewLabel("")
+	presetHint.Wrapping = fyne.TextWrapWord
+	presetHint.Importance = widget.LowImportance
+	presetHint.Hide()
+
+	keyFileBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), g.openKeyFile)
+	keyFileBtn.Disable() // only enabled for providers with key files
+
+	fetchTokenBtn := widget.NewButtonWithIcon("", theme.DownloadIcon(), g.fetchECRToken)
+	fetchTokenBtn.Disable() // only enabled for providers with short-lived tokens
+
+	presetSelect := widget.NewSelect(names, nil)
+	presetSelect.SetSelected(utils.PresetCustom)
+
+	g.presetSelect = presetSelect
+	g.presetHint = presetHint
+	g.keyFileBtn = keyFileBtn
+	g.fetchTokenBtn = fetchTokenBtn
+
+	// set the callback after the initial selection, the entries must not be overwritten at startup
+	presetSelect.OnChanged = g.onPresetChanged
+}
+
+// currentPreset returns the selected registry preset, falls back to the custom preset
+func (g *generator) currentPreset() utils.RegistryPreset {
+	if preset, ok := utils.FindRegistryPreset(g.presetSelect.Selected); ok {
+		return preset
+	}
+	return utils.RegistryPresets()[0]
+}
+
+// onPresetChanged fills the registry host and username of the preset and shows its hint
+func (g *generator) onPresetChanged(string) {
+	preset := g.currentPreset()
+
+	if preset.Host != "" {
+		g.regEntry.SetText(preset.Host)
+	}
+	if preset.Username != "" {
+		g.userEntry.SetText(preset.Username)
+	}
+
+	if preset.Hint != "" {
+		g.presetHint.SetText(preset.Hint)
+		g.presetHint.Show()
+	} else {
+		g.presetHint.SetText("")
+		g.presetHint.Hide()
+	}
+
+	if preset.ReadKeyFile != nil {
+		g.keyFileBtn.Enable()
+	} else {
+		g.keyFileBtn.Disable()
+	}
+
+	if preset.FetchToken {
+		g.fetchTokenBtn.Enable()
+	} else {
+		g.fetchTokenBtn.Disable()
+	}
+
+	g.userEntry.Validator = func(s string) error {
+		if s == "" || preset.CheckUsername == nil {
+			return nil
+		}
+		return preset.CheckUsername(s)
+	}
+	_ = g.userEntry.Validate()
+}
+
+// openKeyFile reads the key file of the selected provider into the password entry
+func (g *generator) openKeyFile() {
+	preset := g.currentPreset()
+	if preset.ReadKeyFile == nil {
+		return
+	}
+
+	openDialog := dialog.NewFileOpen(
+		func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriReader.Close(); err != nil {
+					log.Printf("Key-File - failed to close uriReader: %v", err)
+				}
+			}()
+
+			content, err := io.ReadAll(uriReader)
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+
+			password, err := preset.ReadKeyFile(content, strings.TrimSpace(g.userEntry.Text))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", uriReader.URI().Name(), err), g.window)
+				return
+			}
+
+			g.passEntry.SetText(password)
+			g.showToast("Key file loaded")
+		},
+		g.window,
+	)
+
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	openDialog.SetTitleText("Open Key File")
+	openDialog.Resize(fyne.NewSize(600.0, 400.0))
+	openDialog.Show()
+}
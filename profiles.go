@@ -0,0 +1,326 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+// vaultPath returns the profile vault next to the preferences.json of the app
+func vaultPath() string {
+	return utils.VaultPath(fyne.CurrentApp().Storage().RootURI().Path())
+}
+
+// openProfiles opens the credential store once per session and shows the profile picker
+func (g *generator) openProfiles() {
+	g.readProfiles(g.showProfiles)
+}
+
+// readProfiles opens the credential store if needed and passes its profiles
+func (g *generator) readProfiles(onRead func([]utils.Profile)) {
+	read := func() {
+		var profiles []utils.Profile
+		g.runCredentialStore(func(store utils.CredentialStore) error {
+			var err error
+			profiles, err = store.Profiles()
+			return err
+		}, func() {
+			onRead(profiles)
+		})
+	}
+
+	if g.credStore != nil {
+		read()
+		return
+	}
+	g.openCredentialStore(read)
+}
+
+// openCredentialStore opens the backend chosen in the settings, the automatic backend keeps an existing
+// vault file and otherwise prefers the desktop keyring with the encrypted file as fallback
+func (g *generator) openCredentialStore(onOpened func()) {
+	backend := utils.EffectiveCredentialBackend(g.appSettings.CredentialBackend, utils.VaultExists(vaultPath()))
+
+	if backend != utils.CredentialBackendFile {
+		store, err := utils.ConnectSecretService()
+		if err == nil {
+			g.credStore = store
+			onOpened()
+			return
+		}
+		if backend == utils.CredentialBackendSecretService {
+			dialog.ShowError(err, g.window)
+			g.chooseCredentialBackend()
+			return
+		}
+		log.Printf("Credential-Store - desktop keyring not available, using the encrypted file: %v", err)
+	}
+
+	g.unlockVault(func(vault *utils.Vault) {
+		g.credStore = utils.NewFileCredentialStore(vault)
+		onOpened()
+	})
+}
+
+// closeCredentialStore forgets the opened store, the vault has to be unlocked again
+func (g *generator) closeCredentialStore() {
+	if g.credStore == nil {
+		return
+	}
+	if err := g.credStore.Close(); err != nil {
+		log.Printf("Credential-Store - failed to close %s: %v", g.credStore.Name(), err)
+	}
+	g.credStore = nil
+}
+
+// chooseCredentialBackend lets the user choose where the profiles are stored
+func (g *generator) chooseCredentialBackend() {
+	names := make([]string, 0, len(utils.CredentialBackends))
+	for _, backend := range utils.CredentialBackends {
+		names = append(names, backend.Name)
+	}
+
+	backendSelect := widget.NewSelect(names, nil)
+	backendSelect.SetSelected(utils.CredentialBackendName(g.appSettings.CredentialBackend))
+
+	form := dialog.NewForm("Profile Storage", "Save", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Store profiles in", backendSelect),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			g.setCredentialBackend(utils.CredentialBackendID(backendSelect.Selected))
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(460, 160))
+	form.Show()
+}
+
+// setCredentialBackend switches the backend of the profiles, the opened store is closed
+func (g *generator) setCredentialBackend(backend string) {
+	if backend == g.appSettings.CredentialBackend {
+		return
+	}
+	// profiles are not moved, the other backend starts with its own profiles
+	g.appSettings.CredentialBackend = backend
+	g.closeCredentialStore()
+}
+
+// unlockVault asks for the passphrase, a new vault is created if none exists yet
+func (g *generator) unlockVault(onUnlocked func(*utils.Vault)) {
+	path := vaultPath()
+	exists := utils.VaultExists(path)
+
+	passphraseEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{widget.NewFormItem("Passphrase", passphraseEntry)}
+	title := "Unlock Profiles"
+	if !exists {
+		title = "Create Profile Vault"
+		items = append(items, widget.NewFormItem("Repeat", confirmEntry))
+	}
+
+	form := dialog.NewForm(title, "OK", "Cancel", items,
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			passphrase := passphraseEntry.Text
+			if !exists && passphrase != confirmEntry.Text {
+				dialog.ShowError(errors.New("the passphrases do not match"), g.window)
+				return
+			}
+
+			g.profilesBtn.Disable()
+
+			// the key derivation takes a moment, keep the UI responsive
+			go func() {
+				var vault *utils.Vault
+				var err error
+				if exists {
+					vault, err = utils.OpenVault(path, passphrase)
+				} else {
+					vault, err = utils.CreateVault(path, passphrase, utils.DefaultKDFParams)
+				}
+
+				fyne.Do(func() {
+					g.profilesBtn.Enable()
+					if err != nil {
+						dialog.ShowError(err, g.window)
+						return
+					}
+					onUnlocked(vault)
+				})
+			}()
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(420, 200))
+	form.Show()
+}
+
+// runCredentialStore runs a store action in the background, the keyring may prompt for its password
+func (g *generator) runCredentialStore(action func(utils.CredentialStore) error, onDone func()) {
+	store := g.credStore
+	g.profilesBtn.Disable()
+
+	go func() {
+		err := action(store)
+
+		fyne.Do(func() {
+			g.profilesBtn.Enable()
+			if err != nil {
+				dialog.ShowError(err, g.window)
+				return
+			}
+			onDone()
+		})
+	}()
+}
+
+// teamProfiles returns the profile templates of the team defaults which have no own profile of the same name
+func (g *generator) teamProfiles(own []utils.Profile) []utils.Profile {
+	team := g.appSettings.TeamDefaults()
+	if team == nil {
+		return nil
+	}
+
+	var profiles []utils.Profile
+	for _, template := range team.Profiles {
+		if !slices.ContainsFunc(own, func(p utils.Profile) bool { return p.Name == template.Name }) {
+			profiles = append(profiles, template.Profile())
+		}
+	}
+	return profiles
+}
+
+// showProfiles lists the saved profiles followed by the read-only templates of the team, selecting one fills in the form
+func (g *generator) showProfiles(own []utils.Profile) {
+	var profilesDialog *dialog.CustomDialog
+
+	profiles := slices.Concat(own, g.teamProfiles(own))
+	isTeam := func(id int) bool { return id >= len(own) }
+
+	list := widget.NewList(
+		func() int { return len(profiles) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			p := profiles[id]
+			row := item.(*fyne.Container)
+			label := fmt.Sprintf("%s  (%s@%s)", p.Name, p.Username, p.Registry)
+			deleteBtn := row.Objects[1].(*widget.Button)
+			if isTeam(id) {
+				row.Objects[0].(*widget.Label).SetText(label + "  [team]")
+				deleteBtn.Hide()
+				return
+			}
+			row.Objects[0].(*widget.Label).SetText(label)
+			deleteBtn.Show()
+			deleteBtn.OnTapped = func() {
+				dialog.ShowConfirm("Delete Profile", fmt.Sprintf("Delete the profile %q?", p.Name), func(confirmed bool) {
+					if !confirmed {
+						return
+					}
+					profilesDialog.Hide()
+					g.runCredentialStore(func(store utils.CredentialStore) error {
+						return store.DeleteProfile(p.Name)
+					}, g.openProfiles)
+				}, g.window)
+			}
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		g.applyProfile(profiles[id])
+		profilesDialog.Hide()
+	}
+
+	saveBtn := widget.NewButtonWithIcon("Save current input", theme.DocumentSaveIcon(), func() {
+		profilesDialog.Hide()
+		g.saveProfile()
+	})
+	storageBtn := widget.NewButtonWithIcon("Storage", theme.SettingsIcon(), func() {
+		profilesDialog.Hide()
+		g.chooseCredentialBackend()
+	})
+	lockBtn := widget.NewButtonWithIcon("Lock", theme.LogoutIcon(), func() {
+		profilesDialog.Hide()
+		g.closeCredentialStore()
+	})
+
+	var content fyne.CanvasObject = list
+	if len(profiles) == 0 {
+		content = widget.NewLabel("No profiles saved yet.")
+	}
+
+	storeLabel := widget.NewLabel("Stored in: " + g.credStore.Name())
+	storeLabel.Wrapping = fyne.TextWrapWord
+
+	profilesDialog = dialog.NewCustom("Profiles", "Close",
+		container.NewBorder(storeLabel, container.NewHBox(saveBtn, storageBtn, lockBtn), nil, nil, content),
+		g.window,
+	)
+	profilesDialog.Resize(fyne.NewSize(500, 400))
+	profilesDialog.Show()
+}
+
+// saveProfile stores the current input under a profile name
+func (g *generator) saveProfile() {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g. ghcr team-a")
+	nameEntry.SetText(strings.TrimSpace(g.regEntry.Text))
+
+	form := dialog.NewForm("Save Profile", "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Profile name", nameEntry)},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			profile := utils.Profile{
+				Name:      strings.TrimSpace(nameEntry.Text),
+				Registry:  strings.TrimSpace(g.regEntry.Text),
+				Username:  strings.TrimSpace(g.userEntry.Text),
+				Password:  strings.TrimSpace(g.passEntry.Text),
+				Secret:    strings.TrimSpace(g.nameEntry.Text),
+				Namespace: strings.TrimSpace(g.nameSpaceEntry.Text),
+			}
+			g.runCredentialStore(func(store utils.CredentialStore) error {
+				return store.SaveProfile(profile)
+			}, func() {
+				g.showToast(fmt.Sprintf("Profile %q saved", profile.Name))
+			})
+		},
+		g.window,
+	)
+	form.Resize(fyne.NewSize(420, 160))
+	form.Show()
+}
+
+// applyProfile fills in the form with the values of the profile
+func (g *generator) applyProfile(p utils.Profile) {
+	g.regEntry.SetText(p.Registry)
+	g.userEntry.SetText(p.Username)
+	g.passEntry.SetText(p.Password)
+	g.nameEntry.SetText(p.Secret)
+	g.nameSpaceEntry.SetText(p.Namespace)
+	g.canGenerate()
+	g.showToast(fmt.Sprintf("Profile %q loaded", p.Name))
+}
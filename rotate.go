@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+// RotateFileResult is the rotation result of one manifest file
+type RotateFileResult struct {
+	Path   string
+	Result *pullsecret.RotateResult
+	// the file could not be read or parsed
+	Err error
+}
+
+// RotateFiles replaces the password of the registry and username in every file, a file which can not
+// be read does not stop the others. Nothing is written, see RotateFileResult.Write.
+func RotateFiles(files []string, registry, username, password string) ([]RotateFileResult, error) {
+	if err := pullsecret.ValidateCredentials(registry, username, password); err != nil {
+		return nil, err
+	}
+
+	results := make([]RotateFileResult, 0, len(files))
+	for _, file := range files {
+		result := RotateFileResult{Path: file}
+		content, err := os.ReadFile(file)
+		if err == nil {
+			result.Result, err = pullsecret.Rotate(content, registry, username, password)
+		}
+		result.Err = err
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Changed reports whether the file got a new password
+func (r RotateFileResult) Changed() bool {
+	return r.Err == nil && r.Result.Changed()
+}
+
+// Write writes the rotated manifest back to the file
+func (r RotateFileResult) Write() error {
+	rotated, err := r.Result.Rotated()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.Path, rotated, cliFileMode)
+}
+
+// RotateSummary counts the rotated entries and files
+type RotateSummary struct {
+	Rotated, UpToDate, Skipped, Files, Failed int
+}
+
+// SummarizeRotation counts the rotated entries and files of all results
+func SummarizeRotation(results []RotateFileResult) RotateSummary {
+	var s RotateSummary
+	for _, r := range results {
+		if r.Err != nil {
+			s.Failed++
+			continue
+		}
+		if r.Changed() {
+			s.Files++
+		}
+		s.Skipped += len(r.Result.Skipped)
+		for _, c := range r.Result.Changes {
+			if c.Unchanged {
+				s.UpToDate++
+			} else {
+				s.Rotated++
+			}
+		}
+	}
+	return s
+}
+
+func (s RotateSummary) String() string {
+	return fmt.Sprintf("%d entries rotated in %d files, %d up to date, %d secrets skipped, %d files failed",
+		s.Rotated, s.Files, s.UpToDate, s.Skipped, s.Failed)
+}
+
+// RotateReport returns a diff-like summary: the changed files with one line per entry, - for the
+// skipped secrets, = for entries which are up to date and ~ for rotated ones. Passwords are never
+// shown, not even as hashes.
+func RotateReport(results []RotateFileResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(&b, "%s: ERROR %v\n", r.Path, r.Err)
+			continue
+		}
+		if len(r.Result.Changes) == 0 && len(r.Result.Skipped) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s:\n", r.Path)
+		for _, c := range r.Result.Changes {
+			marker := "~"
+			if c.Unchanged {
+				marker = "="
+			}
+			fmt.Fprintf(&b, "  %s %s\n", marker, c)
+		}
+		for _, f := range r.Result.Skipped {
+			fmt.Fprintf(&b, "  - %s\n", f)
+		}
+	}
+	fmt.Fprintln(&b, SummarizeRotation(results))
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCLI_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, registry, username string) string {
+		secret, err := NewImagePullSecret(registry, username, "old-token", strings.TrimSuffix(name, ".yaml"), "team-a")
+		assert.NoError(t, err)
+		manifest, err := secret.ToYAML()
+		assert.NoError(t, err)
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(manifest), 0o600))
+		return path
+	}
+	rotated := write("ghcr-pull.yaml", "ghcr.io", "ci")
+	otherUser := write("other-pull.yaml", "ghcr.io", "bot")
+	before, err := os.ReadFile(otherUser)
+	assert.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	cliStdin = strings.NewReader("new-token\n")
+	defer func() { cliStdin = os.Stdin }()
+
+	code := runCLI([]string{"rotate", "-registry", "https://ghcr.io", "-username", "ci", "-dry-run", dir}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+	assert.Contains(t, stdout.String(), rotated+":\n  ~ document 1 (ghcr-pull in team-a) ghcr.io ci: password changed\n")
+	assert.NotContains(t, stdout.String(), "token")
+	assert.Contains(t, stdout.String(), "1 entries rotated in 1 files, 0 up to date, 0 secrets skipped, 0 files failed\n")
+	assert.NotContains(t, stdout.String(), otherUser)
+
+	t.Setenv("NEW_TOKEN", "new-token")
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLI([]string{"rotate", "-registry", "ghcr.io", "-username", "ci", "-password", "env:NEW_TOKEN", dir}, &stdout, &stderr))
+
+	content, err := os.ReadFile(rotated)
+	assert.NoError(t, err)
+	secret, err := pullsecret.Parse(content)
+	assert.NoError(t, err)
+	assert.NoError(t, secret.Validate())
+	_, entry, err := secret.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "new-token", entry.Password)
+	assert.Equal(t, "team-a", secret.Metadata.Namespace)
+
+	after, err := os.ReadFile(otherUser)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after, "files without matching entries are not written")
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, runCLI([]string{"rotate", "-registry", "ghcr.io", "-username", "ci", "-password", "env:NEW_TOKEN", dir}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "0 entries rotated in 0 files, 1 up to date")
+
+	assert.Equal(t, exitFailed, runCLI([]string{"rotate", "-registry", "quay.io", "-username", "ci", "-password", "env:NEW_TOKEN", dir}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLI([]string{"rotate", "-registry", "ghcr.io", "-username", "ci", "-password", "new-token", dir}, &stdout, &stderr))
+	assert.Equal(t, exitUsage, runCLI([]string{"rotate", "-registry", "ghcr.io", dir}, &stdout, &stderr))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// openRotate shows a window which replaces the password of a registry user in existing secret files
+func (g *generator) openRotate() {
+	if g.rotateWindow != nil {
+		g.rotateWindow.RequestFocus()
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow("Rotate Credentials")
+	g.rotateWindow = w
+	w.SetOnClosed(func() { g.rotateWindow = nil })
+
+	history := g.appSettings.History
+
+	userEntry := widget.NewSelectEntry(history.RegistryUsernames(g.regEntry.Text))
+	userEntry.SetText(g.userEntry.Text)
+	regEntry := widget.NewSelectEntry(history.SortedRegistries())
+	regEntry.SetText(g.regEntry.Text)
+	regEntry.OnChanged = func(registry string) { userEntry.SetOptions(history.RegistryUsernames(registry)) }
+
+	passEntry := widget.NewPasswordEntry()
+	passEntry.SetPlaceHolder("new password or token")
+
+	var paths []string
+	pathsLabel := widget.NewLabel("No files selected")
+	pathsLabel.Wrapping = fyne.TextWrapWord
+
+	report := widget.NewLabel("Choose the files, the registry and the username, then press Preview.\n" +
+		"Only the matching auths entries are rewritten, passwords are never shown.")
+	report.TextStyle.Monospace = true
+
+	writeBtn := widget.NewButtonWithIcon("Write Changes", theme.DocumentSaveIcon(), nil)
+	writeBtn.Disable() // enabled after a preview with changes
+
+	var results []RotateFileResult
+	preview := func() {
+		writeBtn.Disable()
+		if len(paths) == 0 {
+			dialog.ShowInformation("Rotate Credentials", "Add the secret files or folders first", w)
+			return
+		}
+
+		files, err := ManifestFiles(paths)
+		if err == nil {
+			results, err = RotateFiles(files, strings.TrimSpace(regEntry.Text), strings.TrimSpace(userEntry.Text), passEntry.Text)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		report.SetText(RotateReport(results))
+		if SummarizeRotation(results).Rotated > 0 {
+			writeBtn.Enable()
+		}
+	}
+	previewBtn := widget.NewButtonWithIcon("Preview", theme.SearchIcon(), preview)
+
+	writeBtn.OnTapped = func() {
+		summary := SummarizeRotation(results)
+		message := fmt.Sprintf("Write the new password to %d entries in %d files?", summary.Rotated, summary.Files)
+		dialog.ShowConfirm("Rotate Credentials", message, func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			for _, r := range results {
+				if !r.Changed() {
+					continue
+				}
+				if err := r.Write(); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+			}
+			g.showToast(fmt.Sprintf("Rotated %d entries", summary.Rotated))
+			// shows the rotated entries as up to date
+			preview()
+		}, w)
+	}
+
+	addPath := func(path string) {
+		paths = append(paths, path)
+		pathsLabel.SetText(strings.Join(paths, "\n"))
+		writeBtn.Disable()
+	}
+
+	addFileBtn := widget.NewButtonWithIcon("Add File", theme.FileIcon(), func() {
+		openDialog := dialog.NewFileOpen(func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+			// only the path is needed, the files are read when rotating
+			_ = uriReader.Close()
+			addPath(uriReader.URI().Path())
+		}, w)
+		openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+		openDialog.Resize(fyne.NewSize(600.0, 400.0))
+		openDialog.Show()
+	})
+
+	addFolderBtn := widget.NewButtonWithIcon("Add Folder", theme.FolderOpenIcon(), func() {
+		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uri == nil {
+				// cancelled
+				return
+			}
+			addPath(uri.Path())
+		}, w)
+		folderDialog.Resize(fyne.NewSize(600.0, 400.0))
+		folderDialog.Show()
+	})
+
+	clearBtn := widget.NewButtonWithIcon("", theme.ContentClearIcon(), func() {
+		paths = nil
+		pathsLabel.SetText("No files selected")
+		writeBtn.Disable()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Registry", regEntry),
+		widget.NewFormItem("Username", userEntry),
+		widget.NewFormItem("New password", passEntry),
+	)
+
+	w.SetContent(container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, container.NewHBox(addFileBtn, addFolderBtn, clearBtn), pathsLabel),
+			form,
+			widget.NewSeparator(),
+		),
+		container.NewHBox(previewBtn, writeBtn),
+		nil, nil,
+		container.NewScroll(report),
+	))
+	w.Resize(fyne.NewSize(820, 560))
+	w.Show()
+}
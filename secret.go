@@ -2,121 +2,181 @@ package main
 
 import (
 	"bytes"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/javaLux/registrymate/pkg/pullsecret"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	SecretTypeDockerConfigJSON = "kubernetes.io/dockerconfigjson"
-	APIVersionV1               = "v1"
-	KindSecret                 = "Secret"
-	DataKeyDockerConfigJSON    = ".dockerconfigjson"
+	SecretTypeDockerConfigJSON = pullsecret.TypeDockerConfigJSON
+	SecretTypeOpaque           = "Opaque"
+	APIVersionV1               = pullsecret.APIVersion
+	KindSecret                 = pullsecret.Kind
+	DataKeyDockerConfigJSON    = pullsecret.DataKey
 )
 
-type DockerConfig struct {
-	Auths map[string]AuthEntry `json:"auths"`
-}
+type (
+	DockerConfig = pullsecret.DockerConfig
+	AuthEntry    = pullsecret.AuthEntry
+	Metadata     = pullsecret.Metadata
+)
+
+// Secret is the secret of the pullsecret package, the output formats of the app are its methods
+type Secret pullsecret.Secret
 
-type AuthEntry struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Auth     string `json:"auth"`
+// lib returns the secret as pullsecret.Secret, the conversion does not copy
+func (s *Secret) lib() *pullsecret.Secret {
+	return (*pullsecret.Secret)(s)
 }
 
-type Secret struct {
-	APIVersion string            `yaml:"apiVersion"`
-	Kind       string            `yaml:"kind"`
-	Metadata   Metadata          `yaml:"metadata"`
-	Type       string            `yaml:"type"`
-	Data       map[string]string `yaml:"data"`
+func NewImagePullSecret(registry, user, pass, name, namespace string) (*Secret, error) {
+	secret, err := pullsecret.New(registry, user, pass,
+		pullsecret.WithName(name),
+		pullsecret.WithNamespace(namespace),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return (*Secret)(secret), nil
 }
 
-type Metadata struct {
-	Name      string `yaml:"name"`
-	Namespace string `yaml:"namespace,omitempty"`
+// SecretInput are the raw values entered for one pull secret
+type SecretInput struct {
+	Registry  string
+	Username  string
+	Password  string
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	// name template used if the name is empty or invalid, a random name is generated without it
+	NameTemplate string
+	// checked after the invalid values were replaced, nil allows everything
+	Policy *pullsecret.Policy
 }
 
-func NewImagePullSecret(registry, user, pass, name, namespace string) (*Secret, error) {
-	auth := base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "%s:%s", user, pass))
-
-	cfg := DockerConfig{
-		Auths: map[string]AuthEntry{
-			registry: {
-				Username: user,
-				Password: pass,
-				Auth:     auth,
-			},
-		},
+// NewValidatedPullSecret runs the input checks of the generator and creates the pull secret.
+// An empty or invalid name is replaced by the name template or a generated one and an invalid
+// namespace is dropped, replaced values are returned as warnings.
+// Violations of the policy are returned joined as error, or as warnings if the policy says so.
+func NewValidatedPullSecret(in SecretInput) (*Secret, []string, error) {
+	registry := strings.TrimSpace(in.Registry)
+	user := strings.TrimSpace(in.Username)
+	pass := strings.TrimSpace(in.Password)
+	name := strings.TrimSpace(in.Name)
+	namespace := strings.TrimSpace(in.Namespace)
+
+	var warnings []string
+
+	if namespace != "" {
+		if err := pullsecret.ValidateNamespace(namespace); err != nil {
+			// clear invalid namespace
+			warnings = append(warnings, fmt.Sprintf("%v, %q dropped", err, namespace))
+			namespace = ""
+		}
 	}
 
-	jsonBytes, err := json.Marshal(cfg)
+	if err := pullsecret.ValidateName(name); err != nil {
+		generated, templateErr := generateSecretName(in.NameTemplate, registry, user, namespace)
+		if templateErr != nil {
+			warnings = append(warnings, templateErr.Error())
+		}
+		if name != "" {
+			warnings = append(warnings, fmt.Sprintf("%v, %q replaced by %q", err, name, generated))
+		}
+		name = generated
+	}
+
+	if err := pullsecret.ValidateLabels(in.Labels); err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := NewImagePullSecret(registry, user, pass, name, namespace)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if len(in.Labels) > 0 {
+		secret.Metadata.Labels = in.Labels
 	}
 
-	dockerCfgB64 := base64.StdEncoding.EncodeToString(jsonBytes)
-
-	secret := Secret{
-		APIVersion: APIVersionV1,
-		Kind:       KindSecret,
-		Type:       SecretTypeDockerConfigJSON,
-		Data: map[string]string{
-			DataKeyDockerConfigJSON: dockerCfgB64,
-		},
-		Metadata: Metadata{
-			Name:      name,
-			Namespace: namespace,
-		},
+	violations := in.Policy.Check(secret.lib())
+	if err := violations.Err(); err != nil {
+		return nil, nil, err
+	}
+	for _, warning := range violations.Warnings() {
+		warnings = append(warnings, warning.Error())
 	}
 
-	return &secret, nil
+	return secret, warnings, nil
+}
+
+// generateSecretName renders the name template, a random name is returned without template
+// or together with the error of a failing template
+func generateSecretName(nameTemplate, registry, user, namespace string) (string, error) {
+	if nameTemplate == "" {
+		return pullsecret.GenerateName(), nil
+	}
+
+	name, err := pullsecret.RenderName(nameTemplate, pullsecret.NewNameData(registry, user, namespace, time.Now()))
+	if err != nil {
+		return pullsecret.GenerateName(), err
+	}
+	return name, nil
 }
 
 // ToYAML converts the Secret struct to a YAML string with proper indentation.
 func (s *Secret) ToYAML() (string, error) {
+	return s.ToYAMLWith(pullsecret.EncodeOptions{})
+}
+
+// ToYAMLWith converts the Secret struct to a YAML string written with the given options
+func (s *Secret) ToYAMLWith(opts pullsecret.EncodeOptions) (string, error) {
+	doc, err := s.lib().EncodeWith(opts)
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+// toYAML encodes any manifest to a YAML string with the given indentation, pullsecret.DefaultIndent if 0
+func toYAML(v any, indent int) (string, error) {
+	if indent <= 0 {
+		indent = pullsecret.DefaultIndent
+	}
+
 	var buf bytes.Buffer
 
 	enc := yaml.NewEncoder(&buf)
-	enc.SetIndent(2)
+	enc.SetIndent(indent)
 	defer func() {
 		if err := enc.Close(); err != nil {
 			log.Printf("YAML-Encoder - failed to close encoder: %v", err)
 		}
 	}()
 
-	if err := enc.Encode(s); err != nil {
+	if err := enc.Encode(v); err != nil {
 		return "", err
 	}
 
 	return buf.String(), nil
 }
 
-// DecodeDockerConfig decodes the base64-encoded Docker config JSON from the secret data
-func (s *Secret) DecodeDockerConfig() (string, error) {
-	dockerCfgB64 := s.Data[DataKeyDockerConfigJSON]
+// Credentials returns the registry and auth entry of a secret created by NewImagePullSecret
+func (s *Secret) Credentials() (string, AuthEntry, error) {
+	return s.lib().Credentials()
+}
 
-	// Base64 → JSON
-	dockerCfgJSON, err := base64.StdEncoding.DecodeString(dockerCfgB64)
+// DecodeDockerConfig decodes the base64-encoded Docker config JSON from the secret data, the YAML is
+// written with the given indentation
+func (s *Secret) DecodeDockerConfig(indent int) (string, error) {
+	decoded, err := s.lib().Decoded()
 	if err != nil {
 		return "", err
 	}
 
-	secret := Secret{
-		APIVersion: APIVersionV1,
-		Kind:       KindSecret,
-		Type:       SecretTypeDockerConfigJSON,
-		Data: map[string]string{
-			DataKeyDockerConfigJSON: string(dockerCfgJSON),
-		},
-		Metadata: Metadata{
-			Name:      s.Metadata.Name,
-			Namespace: s.Metadata.Namespace,
-		},
-	}
-
-	return secret.ToYAML()
+	return (*Secret)(decoded).ToYAMLWith(pullsecret.EncodeOptions{Indent: indent})
 }
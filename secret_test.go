@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/javaLux/registrymate/pkg/pullsecret"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,7 +70,7 @@ func TestDecodeDockerConfig(t *testing.T) {
 
 	secret, _ := NewImagePullSecret(registry, user, pass, name, namespace)
 
-	yamlStr, err := secret.DecodeDockerConfig()
+	yamlStr, err := secret.DecodeDockerConfig(0)
 	assert.NoError(t, err)
 	assert.Contains(t, yamlStr, "apiVersion: v1")
 	assert.Contains(t, yamlStr, "kind: Secret")
@@ -78,3 +79,65 @@ func TestDecodeDockerConfig(t *testing.T) {
 	assert.Contains(t, yamlStr, user)
 	assert.Contains(t, yamlStr, pass)
 }
+
+func TestCredentials(t *testing.T) {
+	secret, _ := NewImagePullSecret("ghcr.io", "user", "pass", "mysecret", "")
+
+	registry, entry, err := secret.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "ghcr.io", registry)
+	assert.Equal(t, "user", entry.Username)
+	assert.Equal(t, "pass", entry.Password)
+}
+
+func TestNewValidatedPullSecret_NameTemplate(t *testing.T) {
+	secret, warnings, err := NewValidatedPullSecret(SecretInput{
+		Registry:     "https://GHCR.io/org",
+		Username:     "alice",
+		Password:     "token",
+		Namespace:    "team-a",
+		NameTemplate: "{{.RegistryHost}}-{{.Namespace}}-pull",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "ghcr.io-team-a-pull", secret.Metadata.Name)
+
+	// an invalid template falls back to a generated name
+	secret, warnings, err = NewValidatedPullSecret(SecretInput{
+		Registry:     "ghcr.io",
+		Username:     "alice",
+		Password:     "token",
+		NameTemplate: "{{.Unknown}}",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.NotEmpty(t, secret.Metadata.Name)
+}
+
+func TestNewValidatedPullSecret_Policy(t *testing.T) {
+	policy, err := pullsecret.ParsePolicy([]byte("version: 1\nnamePattern: ^pull-\nrequiredLabels: [owner]\nseverity:\n  requiredLabels: warning\n"))
+	assert.NoError(t, err)
+
+	secret, warnings, err := NewValidatedPullSecret(SecretInput{
+		Registry: "ghcr.io",
+		Username: "alice",
+		Password: "token",
+		Name:     "pull-ghcr",
+		Labels:   map[string]string{"owner": "alice"},
+		Policy:   policy,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, map[string]string{"owner": "alice"}, secret.Metadata.Labels)
+
+	// the replaced name is checked as well
+	_, _, err = NewValidatedPullSecret(SecretInput{Registry: "ghcr.io", Username: "alice", Password: "token", Name: "Pull", Policy: policy})
+	assert.ErrorIs(t, err, pullsecret.ErrPolicy)
+
+	_, warnings, err = NewValidatedPullSecret(SecretInput{Registry: "ghcr.io", Username: "alice", Password: "token", Name: "pull-ghcr", Policy: policy})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"labels is not allowed by the policy: label owner is required"}, warnings)
+
+	_, _, err = NewValidatedPullSecret(SecretInput{Registry: "ghcr.io", Username: "alice", Password: "token", Labels: map[string]string{"a b": "c"}})
+	assert.ErrorIs(t, err, pullsecret.ErrInvalid)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"github.com/javaLux/registrymate/utils"
+)
+
+// openSettings shows a window with the defaults used to generate, show and copy secrets
+func (g *generator) openSettings() {
+	if g.settingsWindow != nil {
+		g.settingsWindow.RequestFocus()
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow("Settings")
+	g.settingsWindow = w
+	w.SetOnClosed(func() { g.settingsWindow = nil })
+
+	settings := g.appSettings
+	history := settings.History
+
+	namespaceEntry := widget.NewEntry()
+	namespaceEntry.SetPlaceHolder("e.g. default (optional)")
+	namespaceEntry.SetText(settings.DefaultNamespace)
+	namespaceEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return pullsecret.ValidateNamespace(strings.TrimSpace(s))
+	}
+
+	labelsEntry := widget.NewEntry()
+	labelsEntry.SetPlaceHolder("e.g. owner=team-a, env=prod (optional)")
+	labelsEntry.SetText(settings.DefaultLabels)
+	labelsEntry.Validator = func(s string) error {
+		_, err := pullsecret.ParseLabels(s)
+		return err
+	}
+
+	formatSelect := widget.NewSelect(outputFormatNames(), nil)
+	formatSelect.SetSelected(settings.DefaultFormat)
+	if formatSelect.Selected == "" {
+		formatSelect.SetSelectedIndex(0)
+	}
+
+	indents := make([]string, 0, len(utils.YAMLIndents))
+	for _, indent := range utils.YAMLIndents {
+		indents = append(indents, strconv.Itoa(indent))
+	}
+	indentSelect := widget.NewSelect(indents, nil)
+	indentSelect.SetSelected(strconv.Itoa(settings.YAMLIndent))
+
+	stringDataChk := widget.NewCheck("Plain JSON in stringData instead of base64 in data", nil)
+	stringDataChk.SetChecked(settings.StringData)
+
+	templateEntry := widget.NewEntry()
+	templateEntry.SetPlaceHolder("e.g. {{.RegistryHost}}-pull (empty for random names)")
+	templateEntry.SetText(settings.NameTemplate)
+	templateEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		_, err := pullsecret.RenderName(strings.TrimSpace(s), sampleNameData)
+		return err
+	}
+
+	toastEntry := widget.NewEntry()
+	toastEntry.SetText(strconv.Itoa(settings.ToastSeconds))
+	toastEntry.Validator = minIntValidator(1)
+
+	copyChk := widget.NewCheck("Copy every generated secret", nil)
+	copyChk.SetChecked(settings.CopyOnGenerate)
+
+	clipboardEntry := widget.NewEntry()
+	clipboardEntry.SetText(strconv.Itoa(settings.ClipboardClearSeconds))
+	clipboardEntry.Validator = minIntValidator(0)
+
+	orderNames := make([]string, 0, len(utils.HistoryOrders))
+	for _, option := range utils.HistoryOrders {
+		orderNames = append(orderNames, option.Name)
+	}
+	orderSelect := widget.NewSelect(orderNames, nil)
+	for _, option := range utils.HistoryOrders {
+		if option.Order == history.Order() {
+			orderSelect.SetSelected(option.Name)
+		}
+	}
+
+	maxHistoryEntry := widget.NewEntry()
+	maxHistoryEntry.SetText(strconv.Itoa(history.MaxEntries()))
+	maxHistoryEntry.Validator = minIntValidator(1)
+
+	backendNames := make([]string, 0, len(utils.CredentialBackends))
+	for _, backend := range utils.CredentialBackends {
+		backendNames = append(backendNames, backend.Name)
+	}
+	backendSelect := widget.NewSelect(backendNames, nil)
+	backendSelect.SetSelected(utils.CredentialBackendName(settings.CredentialBackend))
+
+	teamEntry := widget.NewEntry()
+	teamEntry.SetPlaceHolder("e.g. a registrymate.yaml in a Git checkout (optional)")
+	teamEntry.SetText(settings.TeamDefaultsPath)
+	teamBrowseBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		chooseYAMLFile(w, "Team Defaults", teamEntry.SetText)
+	})
+	teamReloadBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if err := settings.ReloadTeamDefaults(fyne.CurrentApp().Preferences()); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		g.onSettingsChanged()
+		g.reopenSettings()
+	})
+	teamInput := container.NewBorder(nil, nil, nil, container.NewHBox(teamBrowseBtn, teamReloadBtn), teamEntry)
+
+	policyEntry := widget.NewEntry()
+	policyEntry.SetPlaceHolder("e.g. policy.yaml of your organization (optional)")
+	policyEntry.SetText(settings.PolicyPath)
+	policyBrowseBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		chooseYAMLFile(w, "Policy", policyEntry.SetText)
+	})
+	policyReloadBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if err := settings.ReloadPolicy(); err != nil {
+			dialog.ShowError(err, w)
+		}
+		g.onSettingsChanged()
+		g.reopenSettings()
+	})
+	policyInput := container.NewBorder(nil, nil, nil, container.NewHBox(policyBrowseBtn, policyReloadBtn), policyEntry)
+
+	form := &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Default namespace", Widget: namespaceEntry, HintText: "Filled in on start and used for batch rows without namespace"},
+			{Text: "Default labels", Widget: labelsEntry, HintText: "Filled in on start and used for batch rows without labels"},
+			{Text: "Default output format", Widget: formatSelect, HintText: "Selected on start"},
+			{Text: "YAML indentation", Widget: indentSelect},
+			{Text: "Secret data", Widget: stringDataChk},
+			{Text: "Secret name template", Widget: templateEntry, HintText: strings.Join(pullsecret.NameTemplatePlaceholders, " ")},
+			{Text: "Notification seconds", Widget: toastEntry},
+			{Text: "Clipboard", Widget: copyChk},
+			{Text: "Clear clipboard after", Widget: clipboardEntry, HintText: "Seconds, 0 keeps copied secrets"},
+			{Text: "History order", Widget: orderSelect},
+			{Text: "Entries per list", Widget: maxHistoryEntry},
+			{Text: "Store profiles in", Widget: backendSelect},
+			{Text: "Team defaults", Widget: teamInput, HintText: teamDefaultsStatus(settings)},
+			{Text: "Policy", Widget: policyInput, HintText: policyStatus(settings)},
+		},
+		SubmitText: "Save",
+		OnCancel:   w.Close,
+	}
+	form.OnSubmit = func() {
+		settings.DefaultNamespace = strings.TrimSpace(namespaceEntry.Text)
+		settings.DefaultLabels = strings.TrimSpace(labelsEntry.Text)
+		settings.DefaultFormat = formatSelect.Selected
+		settings.YAMLIndent, _ = strconv.Atoi(indentSelect.Selected)
+		settings.StringData = stringDataChk.Checked
+		settings.NameTemplate = strings.TrimSpace(templateEntry.Text)
+		settings.ToastSeconds, _ = strconv.Atoi(strings.TrimSpace(toastEntry.Text))
+		settings.CopyOnGenerate = copyChk.Checked
+		settings.ClipboardClearSeconds, _ = strconv.Atoi(strings.TrimSpace(clipboardEntry.Text))
+
+		for _, option := range utils.HistoryOrders {
+			if option.Name == orderSelect.Selected {
+				history.SetOrder(option.Order)
+			}
+		}
+		maxEntries, _ := strconv.Atoi(strings.TrimSpace(maxHistoryEntry.Text))
+		history.SetMaxEntries(maxEntries)
+
+		g.setCredentialBackend(utils.CredentialBackendID(backendSelect.Selected))
+
+		// persist right away, the main window saves again on exit
+		settings.SaveAppSettings(fyne.CurrentApp())
+
+		// compared before the team defaults change, they may set another policy
+		policyPath := strings.TrimSpace(policyEntry.Text)
+		policyChanged := policyPath != settings.PolicyPath
+
+		if path := strings.TrimSpace(teamEntry.Text); path != settings.TeamDefaultsPath {
+			if err := settings.SetTeamDefaults(fyne.CurrentApp().Preferences(), path); err != nil {
+				dialog.ShowError(err, g.window)
+			}
+		}
+		if policyChanged {
+			if err := settings.SetPolicy(fyne.CurrentApp().Preferences(), policyPath); err != nil {
+				dialog.ShowError(err, g.window)
+			}
+		}
+
+		g.onSettingsChanged()
+		w.Close()
+		g.showToast("Settings saved")
+	}
+
+	exportBtn := widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), func() { g.exportSettings(w) })
+	importBtn := widget.NewButtonWithIcon("Import", theme.FolderOpenIcon(), func() { g.importSettings(w, g.reopenSettings) })
+
+	w.SetContent(container.NewBorder(nil, container.NewPadded(container.NewHBox(importBtn, exportBtn)), nil, nil,
+		container.NewVScroll(container.NewPadded(form))))
+	w.Resize(fyne.NewSize(620, 640))
+	w.Show()
+}
+
+// onSettingsChanged updates the main window after the settings changed
+func (g *generator) onSettingsChanged() {
+	g.updateEntries()
+	// the shown secret may depend on the YAML options
+	g.onFormatChanged(g.formatSelect.Selected)
+	// the policy may have changed
+	g.checkPolicy()
+}
+
+// reopenSettings shows the settings window again with the current values
+func (g *generator) reopenSettings() {
+	if g.settingsWindow != nil {
+		g.settingsWindow.Close()
+	}
+	g.openSettings()
+}
+
+// teamDefaultsStatus describes the loaded team defaults
+func teamDefaultsStatus(settings *utils.AppSettings) string {
+	if err := settings.TeamDefaultsError(); err != nil {
+		return "Not loaded: " + err.Error()
+	}
+	team := settings.TeamDefaults()
+	if team == nil {
+		return "Read-only settings file, your own settings take precedence"
+	}
+	return fmt.Sprintf("Loaded, %d profile templates", len(team.Profiles))
+}
+
+// policyStatus describes the loaded policy
+func policyStatus(settings *utils.AppSettings) string {
+	if err := settings.PolicyError(); err != nil {
+		return "Not loaded, secrets are not checked: " + err.Error()
+	}
+	if settings.Policy() == nil {
+		return "Rules every generated secret is checked against"
+	}
+	return "Loaded, generated secrets are checked"
+}
+
+// chooseYAMLFile lets the user pick a YAML file, e.g. the team defaults
+func chooseYAMLFile(parent fyne.Window, title string, onChosen func(path string)) {
+	openDialog := dialog.NewFileOpen(
+		func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+			// only the path is needed, the file is read on save
+			_ = uriReader.Close()
+			onChosen(uriReader.URI().Path())
+		},
+		parent,
+	)
+
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+	openDialog.SetTitleText(title)
+	openDialog.Resize(fyne.NewSize(600.0, 400.0))
+	openDialog.Show()
+}
+
+// minIntValidator accepts whole numbers of at least minimum
+func minIntValidator(minimum int) fyne.StringValidator {
+	return func(s string) error {
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err != nil || n < minimum {
+			return fmt.Errorf("must be a number of at least %d", minimum)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/javaLux/registrymate/utils"
+)
+
+const settingsFileName = "registrymate-settings.yaml"
+
+// exportSettings asks which parts to share and saves them as one YAML file, passwords are never exported
+func (g *generator) exportSettings(parent fyne.Window) {
+	settingsChk := widget.NewCheck("Settings", nil)
+	settingsChk.SetChecked(true)
+	historyChk := widget.NewCheck("History", nil)
+	profilesChk := widget.NewCheck("Profiles without passwords", nil)
+
+	form := dialog.NewForm("Export Settings", "Export", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Include", settingsChk),
+			widget.NewFormItem("", historyChk),
+			widget.NewFormItem("", profilesChk),
+		},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			var file utils.SettingsFile
+			if settingsChk.Checked {
+				file.Settings = g.appSettings.Values()
+			}
+			if historyChk.Checked {
+				file.History = g.appSettings.History
+			}
+			if !profilesChk.Checked {
+				g.saveSettingsFile(parent, file)
+				return
+			}
+
+			g.readProfiles(func(profiles []utils.Profile) {
+				for _, p := range profiles {
+					file.Profiles = append(file.Profiles, utils.NewProfileTemplate(p))
+				}
+				g.saveSettingsFile(parent, file)
+			})
+		},
+		parent,
+	)
+	form.Resize(fyne.NewSize(380, 220))
+	form.Show()
+}
+
+// saveSettingsFile writes the settings file chosen in a save dialog
+func (g *generator) saveSettingsFile(parent fyne.Window, file utils.SettingsFile) {
+	content, err := utils.MarshalSettingsFile(file)
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(
+		func(uriWriter fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+			if uriWriter == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriWriter.Close(); err != nil {
+					log.Printf("File-Save - failed to close uriWriter: %v", err)
+				}
+			}()
+
+			originalPath := uriWriter.URI().Path()
+
+			// delete the empty file created by the dialog
+			_ = os.Remove(originalPath)
+
+			if err := utils.WriteFile(utils.EnsureYAMLExt(originalPath), content); err != nil {
+				dialog.ShowError(err, parent)
+			} else {
+				g.showToast("Settings exported")
+			}
+		},
+		parent,
+	)
+
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+	saveDialog.SetFileName(settingsFileName)
+	saveDialog.SetTitleText("Export Settings")
+	saveDialog.Resize(fyne.NewSize(600.0, 400.0))
+	saveDialog.Show()
+}
+
+// importSettings reads a settings file and applies the parts the user confirms
+func (g *generator) importSettings(parent fyne.Window, onImported func()) {
+	openDialog := dialog.NewFileOpen(
+		func(uriReader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+			if uriReader == nil {
+				// cancelled
+				return
+			}
+
+			defer func() {
+				if err := uriReader.Close(); err != nil {
+					log.Printf("Settings-Import - failed to close uriReader: %v", err)
+				}
+			}()
+
+			content, err := io.ReadAll(uriReader)
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+
+			file, err := utils.ParseSettingsFile(content)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", uriReader.URI().Name(), err), parent)
+				return
+			}
+
+			g.confirmSettingsImport(parent, file, onImported)
+		},
+		parent,
+	)
+
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+	openDialog.SetTitleText("Import Settings")
+	openDialog.Resize(fyne.NewSize(600.0, 400.0))
+	openDialog.Show()
+}
+
+// confirmSettingsImport lets the user choose which parts of the file are applied
+func (g *generator) confirmSettingsImport(parent fyne.Window, file *utils.SettingsFile, onImported func()) {
+	var items []*widget.FormItem
+	settingsChk := widget.NewCheck("Settings, own settings are replaced", nil)
+	historyChk := widget.NewCheck("History, merged into the own history", nil)
+	profilesChk := widget.NewCheck(fmt.Sprintf("%d profiles without passwords, existing names are kept", len(file.Profiles)), nil)
+	for _, part := range []struct {
+		check   *widget.Check
+		present bool
+	}{
+		{settingsChk, file.Settings != nil},
+		{historyChk, file.History != nil},
+		{profilesChk, len(file.Profiles) > 0},
+	} {
+		if part.present {
+			part.check.SetChecked(true)
+			items = append(items, widget.NewFormItem("", part.check))
+		}
+	}
+	if len(items) == 0 {
+		dialog.ShowInformation("Import Settings", "The file contains nothing to import.", parent)
+		return
+	}
+	items[0].Text = "Import"
+
+	form := dialog.NewForm("Import Settings", "Import", "Cancel", items,
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			if settingsChk.Checked {
+				g.appSettings.Apply(file.Settings)
+			}
+			if historyChk.Checked {
+				g.appSettings.History.Merge(file.History)
+			}
+			g.appSettings.SaveAppSettings(fyne.CurrentApp())
+			g.updateEntries()
+			g.onFormatChanged(g.formatSelect.Selected)
+
+			if !profilesChk.Checked {
+				g.showToast("Settings imported")
+				onImported()
+				return
+			}
+			g.importProfileTemplates(file.Profiles, onImported)
+		},
+		parent,
+	)
+	form.Resize(fyne.NewSize(480, 240))
+	form.Show()
+}
+
+// importProfileTemplates saves the templates whose names are not used by a profile yet
+func (g *generator) importProfileTemplates(templates []utils.ProfileTemplate, onImported func()) {
+	g.readProfiles(func(profiles []utils.Profile) {
+		added := 0
+		g.runCredentialStore(func(store utils.CredentialStore) error {
+			for _, template := range templates {
+				if slices.ContainsFunc(profiles, func(p utils.Profile) bool { return p.Name == template.Name }) {
+					continue
+				}
+				if err := store.SaveProfile(template.Profile()); err != nil {
+					return err
+				}
+				added++
+			}
+			return nil
+		}, func() {
+			g.showToast(fmt.Sprintf("Settings imported, %d profiles added", added))
+			onImported()
+		})
+	})
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SOPSVersion is written to the sops metadata block of encrypted files
+	SOPSVersion = "3.9.0"
+	// SOPSEncryptedRegex restricts encryption to the values below data and stringData
+	SOPSEncryptedRegex = `^(data|stringData)$`
+	// sops uses AES-256-GCM with a 32 byte nonce for every value
+	sopsNonceSize = 32
+)
+
+// ParseAgeRecipients parses age public keys separated by commas, spaces or new lines
+func ParseAgeRecipients(s string) ([]age.Recipient, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+
+	recipients := make([]age.Recipient, 0, len(fields))
+	for _, field := range fields {
+		recipient, err := age.ParseX25519Recipient(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", field, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one age recipient is required")
+	}
+
+	return recipients, nil
+}
+
+// ToSOPS encrypts the secret in SOPS format for the given age recipients.
+// Only the values below data and stringData are encrypted, everything else stays in clear text.
+// The YAML is written with the given indentation, pullsecret.DefaultIndent if 0.
+func (s *Secret) ToSOPS(recipients string, indent int) (string, error) {
+	if indent <= 0 {
+		indent = pullsecret.DefaultIndent
+	}
+
+	ageRecipients, err := ParseAgeRecipients(recipients)
+	if err != nil {
+		return "", err
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(s); err != nil {
+		return "", err
+	}
+
+	if err := sopsEncrypt(&doc, ageRecipients, time.Now()); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// sopsEncrypt encrypts the matching values of the mapping node in place and appends the sops metadata block
+func sopsEncrypt(doc *yaml.Node, recipients []age.Recipient, now time.Time) error {
+	if doc.Kind != yaml.MappingNode {
+		return fmt.Errorf("sops: document must be a mapping")
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+
+	encryptedRegex := regexp.MustCompile(SOPSEncryptedRegex)
+
+	// the MAC covers every value of the document in order of appearance
+	mac := sha512.New()
+	if err := sopsWalk(doc, nil, false, encryptedRegex, func(node *yaml.Node, path []string, encrypt bool) error {
+		mac.Write([]byte(node.Value))
+		if !encrypt {
+			return nil
+		}
+		value, err := sopsEncryptValue(node.Value, dataKey, strings.Join(path, ":")+":")
+		if err != nil {
+			return err
+		}
+		node.Value = value
+		node.Tag = "!!str"
+		node.Style = 0
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	lastModified := now.UTC().Format(time.RFC3339)
+	encryptedMAC, err := sopsEncryptValue(fmt.Sprintf("%X", mac.Sum(nil)), dataKey, lastModified)
+	if err != nil {
+		return err
+	}
+
+	ageKeys := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, recipient := range recipients {
+		encryptedKey, err := ageEncryptArmored(dataKey, recipient)
+		if err != nil {
+			return err
+		}
+		ageKeys.Content = append(ageKeys.Content, mappingNode(
+			"recipient", fmt.Sprint(recipient),
+			"enc", encryptedKey,
+		))
+	}
+
+	metadata := mappingNode(
+		"lastmodified", lastModified,
+		"mac", encryptedMAC,
+		"encrypted_regex", SOPSEncryptedRegex,
+		"version", SOPSVersion,
+	)
+	metadata.Content = append([]*yaml.Node{scalarNode("age"), ageKeys}, metadata.Content...)
+
+	doc.Content = append(doc.Content, scalarNode("sops"), metadata)
+
+	return nil
+}
+
+// sopsWalk calls fn for every scalar value, reporting its key path and whether it has to be encrypted
+func sopsWalk(node *yaml.Node, path []string, encrypt bool, re *regexp.Regexp, fn func(*yaml.Node, []string, bool) error) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if err := sopsWalk(node.Content[i+1], append(path[:len(path):len(path)], key), encrypt || re.MatchString(key), re, fn); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := sopsWalk(item, path, encrypt, re, fn); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return fn(node, path, encrypt)
+	}
+	return nil
+}
+
+// sopsEncryptValue encrypts a single string value like sops does: ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]
+func sopsEncryptValue(plaintext string, key []byte, additionalData string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCMWithNonceSize(block, sopsNonceSize)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, sopsNonceSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	out := gcm.Seal(nil, iv, []byte(plaintext), []byte(additionalData))
+	tagStart := len(out) - gcm.Overhead()
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:str]",
+		base64.StdEncoding.EncodeToString(out[:tagStart]),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(out[tagStart:]),
+	), nil
+}
+
+// ageEncryptArmored encrypts the data key for a single recipient as an armored age file
+func ageEncryptArmored(dataKey []byte, recipient age.Recipient) (string, error) {
+	var buf bytes.Buffer
+
+	armorWriter := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armorWriter, recipient)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, bytes.NewReader(dataKey)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := armorWriter.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// mappingNode builds a mapping node from alternating keys and values
+func mappingNode(keyValues ...string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		node.Content = append(node.Content, scalarNode(keyValues[i]), scalarNode(keyValues[i+1]))
+	}
+	return node
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type sopsFile struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   Metadata          `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+	Sops       struct {
+		Age []struct {
+			Recipient string `yaml:"recipient"`
+			Enc       string `yaml:"enc"`
+		} `yaml:"age"`
+		LastModified   string `yaml:"lastmodified"`
+		Mac            string `yaml:"mac"`
+		EncryptedRegex string `yaml:"encrypted_regex"`
+		Version        string `yaml:"version"`
+	} `yaml:"sops"`
+}
+
+// sopsDecryptValue reverses sopsEncryptValue
+func sopsDecryptValue(t *testing.T, value string, key []byte, additionalData string) string {
+	re := regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.*),tag:(.*),type:str\]$`)
+	m := re.FindStringSubmatch(value)
+	assert.Len(t, m, 4, "value must be sops encrypted: %s", value)
+
+	data, _ := base64.StdEncoding.DecodeString(m[1])
+	iv, _ := base64.StdEncoding.DecodeString(m[2])
+	tag, _ := base64.StdEncoding.DecodeString(m[3])
+
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	assert.NoError(t, err)
+
+	plain, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	assert.NoError(t, err)
+	return string(plain)
+}
+
+func TestToSOPS(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	secret, _ := NewImagePullSecret("docker.io", "user", "pass", "mysecret", "default")
+
+	out, err := secret.ToSOPS(identity.Recipient().String(), 0)
+	assert.NoError(t, err)
+
+	var file sopsFile
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &file))
+
+	indented, err := secret.ToSOPS(identity.Recipient().String(), 4)
+	assert.NoError(t, err)
+	assert.Contains(t, indented, "metadata:\n    name: mysecret\n")
+
+	// metadata stays in clear text
+	assert.Equal(t, "mysecret", file.Metadata.Name)
+	assert.Equal(t, "default", file.Metadata.Namespace)
+	assert.Equal(t, SecretTypeDockerConfigJSON, file.Type)
+	assert.Equal(t, SOPSEncryptedRegex, file.Sops.EncryptedRegex)
+	assert.Equal(t, SOPSVersion, file.Sops.Version)
+	assert.Len(t, file.Sops.Age, 1)
+	assert.Equal(t, identity.Recipient().String(), file.Sops.Age[0].Recipient)
+
+	// the data key can be decrypted with the age identity
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(file.Sops.Age[0].Enc)), identity)
+	assert.NoError(t, err)
+	dataKey, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Len(t, dataKey, 32)
+
+	// the data value is encrypted with its key path as additional data
+	encrypted := file.Data[DataKeyDockerConfigJSON]
+	assert.True(t, strings.HasPrefix(encrypted, "ENC[AES256_GCM,"))
+	decrypted := sopsDecryptValue(t, encrypted, dataKey, "data:"+DataKeyDockerConfigJSON+":")
+	assert.Equal(t, secret.Data[DataKeyDockerConfigJSON], decrypted)
+
+	// the MAC covers all values in document order
+	mac := sha512.New()
+	for _, v := range []string{APIVersionV1, KindSecret, "mysecret", "default", SecretTypeDockerConfigJSON, decrypted} {
+		mac.Write([]byte(v))
+	}
+	assert.Equal(t, fmt.Sprintf("%X", mac.Sum(nil)), sopsDecryptValue(t, file.Sops.Mac, dataKey, file.Sops.LastModified))
+}
+
+func TestToSOPS_MultipleRecipients(t *testing.T) {
+	first, _ := age.GenerateX25519Identity()
+	second, _ := age.GenerateX25519Identity()
+
+	secret, _ := NewImagePullSecret("docker.io", "user", "pass", "mysecret", "")
+
+	out, err := secret.ToSOPS(first.Recipient().String()+",\n"+second.Recipient().String(), 0)
+	assert.NoError(t, err)
+
+	var file sopsFile
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &file))
+	assert.Len(t, file.Sops.Age, 2)
+	assert.Equal(t, second.Recipient().String(), file.Sops.Age[1].Recipient)
+}
+
+func TestParseAgeRecipients_Invalid(t *testing.T) {
+	_, err := ParseAgeRecipients("")
+	assert.Error(t, err)
+
+	_, err = ParseAgeRecipients("age1invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsVariableNameValid reports whether name can be used as Terraform or Ansible variable name
+func IsVariableNameValid(name string) bool {
+	return variableNameRegex.MatchString(name)
+}
+
+// quoteHCL returns s as HCL string literal, template sequences are escaped
+func quoteHCL(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// terraformResourceName converts a secret name into a valid Terraform resource name
+func terraformResourceName(name string) string {
+	resourceName := strings.ReplaceAll(name, ".", "_")
+	if resourceName == "" || !variableNameRegex.MatchString(resourceName[:1]) {
+		resourceName = "secret_" + resourceName
+	}
+	return resourceName
+}
+
+// TerraformResource returns a kubernetes_secret resource with the same metadata as NewImagePullSecret.
+// If passwordVar is set, the password is read from that Terraform variable instead of a literal.
+func TerraformResource(registry, user, pass, name, namespace, passwordVar string) (string, error) {
+	if passwordVar != "" && !IsVariableNameValid(passwordVar) {
+		return "", fmt.Errorf("invalid Terraform variable name %q", passwordVar)
+	}
+
+	password := quoteHCL(pass)
+	if passwordVar != "" {
+		password = "var." + passwordVar
+	}
+
+	var b strings.Builder
+
+	if passwordVar != "" {
+		fmt.Fprintf(&b, "variable %q {\n", passwordVar)
+		b.WriteString("  type      = string\n")
+		b.WriteString("  sensitive = true\n")
+		b.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(&b, "resource \"kubernetes_secret\" %q {\n", terraformResourceName(name))
+	b.WriteString("  metadata {\n")
+	if namespace != "" {
+		fmt.Fprintf(&b, "    name      = %s\n", quoteHCL(name))
+		fmt.Fprintf(&b, "    namespace = %s\n", quoteHCL(namespace))
+	} else {
+		fmt.Fprintf(&b, "    name = %s\n", quoteHCL(name))
+	}
+	b.WriteString("  }\n\n")
+	fmt.Fprintf(&b, "  type = %s\n\n", quoteHCL(SecretTypeDockerConfigJSON))
+	b.WriteString("  data = {\n")
+	fmt.Fprintf(&b, "    %s = jsonencode({\n", quoteHCL(DataKeyDockerConfigJSON))
+	b.WriteString("      auths = {\n")
+	fmt.Fprintf(&b, "        %s = {\n", quoteHCL(registry))
+	fmt.Fprintf(&b, "          username = %s\n", quoteHCL(user))
+	fmt.Fprintf(&b, "          password = %s\n", password)
+	fmt.Fprintf(&b, "          auth     = base64encode(join(\":\", [%s, %s]))\n", quoteHCL(user), password)
+	b.WriteString("        }\n")
+	b.WriteString("      }\n")
+	b.WriteString("    })\n")
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+// TerraformResource returns the kubernetes_secret resource for the registry credentials of the secret
+func (s *Secret) TerraformResource(passwordVar string) (string, error) {
+	registry, auth, err := s.Credentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docker config: %w", err)
+	}
+
+	return TerraformResource(registry, auth.Username, auth.Password, s.Metadata.Name, s.Metadata.Namespace, passwordVar)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerraformResource(t *testing.T) {
+	hcl, err := TerraformResource("registry.example.com", "robot$ci", `pa"ss${x}`, "my.secret", "apps", "")
+	assert.NoError(t, err)
+
+	assert.Contains(t, hcl, `resource "kubernetes_secret" "my_secret" {`)
+	assert.Contains(t, hcl, `    name      = "my.secret"`)
+	assert.Contains(t, hcl, `    namespace = "apps"`)
+	assert.Contains(t, hcl, `  type = "kubernetes.io/dockerconfigjson"`)
+	assert.Contains(t, hcl, `    ".dockerconfigjson" = jsonencode({`)
+	assert.Contains(t, hcl, `        "registry.example.com" = {`)
+	assert.Contains(t, hcl, `          username = "robot$ci"`)
+	assert.Contains(t, hcl, `          password = "pa\"ss$${x}"`)
+	assert.Contains(t, hcl, `          auth     = base64encode(join(":", ["robot$ci", "pa\"ss$${x}"]))`)
+	assert.NotContains(t, hcl, "variable")
+}
+
+func TestTerraformResource_PasswordVariable(t *testing.T) {
+	hcl, err := TerraformResource("docker.io", "user", "secret", "1secret", "", "registry_password")
+	assert.NoError(t, err)
+
+	assert.Contains(t, hcl, `variable "registry_password" {`)
+	assert.Contains(t, hcl, `  sensitive = true`)
+	assert.Contains(t, hcl, `resource "kubernetes_secret" "secret_1secret" {`)
+	assert.Contains(t, hcl, `    name = "1secret"`)
+	assert.Contains(t, hcl, `          password = var.registry_password`)
+	assert.Contains(t, hcl, `          auth     = base64encode(join(":", ["user", var.registry_password]))`)
+	assert.NotContains(t, hcl, `"secret"`)
+
+	_, err = TerraformResource("docker.io", "user", "secret", "mysecret", "", "invalid-name")
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// backends of the credential store, stored in the app settings
+const (
+	// CredentialBackendAuto uses the encrypted file if it exists, otherwise the desktop keyring if
+	// available and the encrypted file as fallback
+	CredentialBackendAuto          = ""
+	CredentialBackendFile          = "file"
+	CredentialBackendSecretService = "secret-service"
+)
+
+// CredentialBackends lists the selectable backends with their display names
+var CredentialBackends = []struct {
+	ID   string
+	Name string
+}{
+	{ID: CredentialBackendAuto, Name: "Automatic"},
+	{ID: CredentialBackendSecretService, Name: "Desktop keyring (Secret Service)"},
+	{ID: CredentialBackendFile, Name: "Encrypted file"},
+}
+
+// ErrSecretServiceUnavailable is returned if no Secret Service is reachable on the session bus
+var ErrSecretServiceUnavailable = errors.New("no Secret Service available on the session bus")
+
+// CredentialStore keeps the credential profiles, passwords never leave the store unencrypted on disk
+type CredentialStore interface {
+	// Name describes the backend, e.g. "Desktop keyring (Secret Service)"
+	Name() string
+	// Profiles returns all profiles sorted by name
+	Profiles() ([]Profile, error)
+	// SaveProfile adds or replaces the profile with the same name
+	SaveProfile(p Profile) error
+	// DeleteProfile removes the profile with the given name
+	DeleteProfile(name string) error
+	// Close releases the resources of the backend
+	Close() error
+}
+
+// CredentialBackendName returns the display name of a backend
+func CredentialBackendName(id string) string {
+	for _, backend := range CredentialBackends {
+		if backend.ID == id {
+			return backend.Name
+		}
+	}
+	return id
+}
+
+// CredentialBackendID returns the backend of a display name, the automatic backend if unknown
+func CredentialBackendID(name string) string {
+	for _, backend := range CredentialBackends {
+		if backend.Name == name {
+			return backend.ID
+		}
+	}
+	return CredentialBackendAuto
+}
+
+// EffectiveCredentialBackend resolves the automatic backend: an existing vault file keeps being used,
+// so the profiles of older versions do not disappear when a desktop keyring becomes available.
+// The automatic backend is returned if the desktop keyring should be tried first.
+func EffectiveCredentialBackend(backend string, vaultExists bool) string {
+	if backend == CredentialBackendAuto && vaultExists {
+		return CredentialBackendFile
+	}
+	return backend
+}
+
+// FileCredentialStore keeps the profiles in the encrypted vault file
+type FileCredentialStore struct {
+	vault *Vault
+}
+
+// NewFileCredentialStore returns the store of an unlocked vault
+func NewFileCredentialStore(vault *Vault) *FileCredentialStore {
+	return &FileCredentialStore{vault: vault}
+}
+
+func (s *FileCredentialStore) Name() string {
+	return fmt.Sprintf("%s (%s)", CredentialBackendName(CredentialBackendFile), s.vault.Path())
+}
+
+func (s *FileCredentialStore) Profiles() ([]Profile, error) {
+	return s.vault.Profiles(), nil
+}
+
+func (s *FileCredentialStore) SaveProfile(p Profile) error {
+	if err := s.vault.SetProfile(p); err != nil {
+		return err
+	}
+	return s.vault.Save()
+}
+
+func (s *FileCredentialStore) DeleteProfile(name string) error {
+	s.vault.RemoveProfile(name)
+	return s.vault.Save()
+}
+
+func (s *FileCredentialStore) Close() error {
+	return nil
+}
@@ -0,0 +1,320 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ECRUsername = "AWS"
+	// ECR authorization tokens are valid for 12 hours
+	ECRTokenLifetime = 12 * time.Hour
+
+	ecrService         = "ecr"
+	ecrTarget          = "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken"
+	ecrContentType     = "application/x-amz-json-1.1"
+	sigV4Algorithm     = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat    = "20060102T150405Z"
+	sigV4DateFormat    = "20060102"
+	defaultAWSProfile  = "default"
+	maxECRResponseSize = 1 << 20
+)
+
+var ecrHostRegex = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// AWSCredentials are the static credentials used to sign requests
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ECRToken is the result of GetAuthorizationToken
+type ECRToken struct {
+	Username string
+	Password string
+	// registry URL the token is valid for, e.g. https://123456789012.dkr.ecr.eu-central-1.amazonaws.com
+	ProxyEndpoint string
+	ExpiresAt     time.Time
+}
+
+// ParseECRHost extracts the account ID and region from an ECR registry host
+func ParseECRHost(registry string) (account, region string, ok bool) {
+	host := registry
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	m := ecrHostRegex.FindStringSubmatch(host)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// ECREndpoint returns the default API endpoint of ECR in the given region, the regions of the
+// China partition (cn-north-1, cn-northwest-1) use the amazonaws.com.cn domain
+func ECREndpoint(region string) string {
+	domain := "amazonaws.com"
+	if strings.HasPrefix(region, "cn-") {
+		domain = "amazonaws.com.cn"
+	}
+	return fmt.Sprintf("https://api.ecr.%s.%s", region, domain)
+}
+
+// LoadAWSProfile reads the credentials of a profile from the shared credentials file
+// (~/.aws/credentials or $AWS_SHARED_CREDENTIALS_FILE) and its region from the config file
+// (~/.aws/config or $AWS_CONFIG_FILE). The region is empty if the profile has none.
+func LoadAWSProfile(profile string) (AWSCredentials, string, error) {
+	if profile == "" {
+		profile = defaultAWSProfile
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return AWSCredentials{}, "", err
+	}
+
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = filepath.Join(home, ".aws", "credentials")
+	}
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = filepath.Join(home, ".aws", "config")
+	}
+
+	sections, err := readINIFile(credentialsFile)
+	if err != nil {
+		return AWSCredentials{}, "", err
+	}
+
+	section, ok := sections[profile]
+	if !ok {
+		return AWSCredentials{}, "", fmt.Errorf("profile %q not found in %s", profile, credentialsFile)
+	}
+
+	creds := AWSCredentials{
+		AccessKeyID:     section["aws_access_key_id"],
+		SecretAccessKey: section["aws_secret_access_key"],
+		SessionToken:    section["aws_session_token"],
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, "", fmt.Errorf("profile %q has no static access keys", profile)
+	}
+
+	// the config file is optional, it only provides the default region
+	region := ""
+	if config, err := readINIFile(configFile); err == nil {
+		configSection := "profile " + profile
+		if profile == defaultAWSProfile {
+			configSection = defaultAWSProfile
+		}
+		region = config[configSection]["region"]
+	}
+
+	return creds, region, nil
+}
+
+// readINIFile parses the simple INI format of the AWS shared config files
+func readINIFile(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	sections := map[string]map[string]string{}
+	current := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if sections[current] == nil {
+				sections[current] = map[string]string{}
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found || current == "" {
+			continue
+		}
+		sections[current][strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return sections, scanner.Err()
+}
+
+// GetECRAuthorizationToken calls the ECR GetAuthorizationToken API at the given endpoint,
+// the request is signed with AWS Signature Version 4
+func GetECRAuthorizationToken(ctx context.Context, client *http.Client, endpoint, region string, creds AWSCredentials) (*ECRToken, error) {
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is required")
+	}
+	if endpoint == "" {
+		endpoint = ECREndpoint(region)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	body := []byte("{}")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", ecrContentType)
+	req.Header.Set("X-Amz-Target", ecrTarget)
+
+	SignV4(req, body, creds, region, ecrService, time.Now())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxECRResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("ECR: %s: %s", apiErr.Type, apiErr.Message)
+		}
+		return nil, fmt.Errorf("ECR: unexpected status %s", resp.Status)
+	}
+
+	var result struct {
+		AuthorizationData []struct {
+			AuthorizationToken string  `json:"authorizationToken"`
+			ExpiresAt          float64 `json:"expiresAt"`
+			ProxyEndpoint      string  `json:"proxyEndpoint"`
+		} `json:"authorizationData"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("ECR: invalid response: %w", err)
+	}
+	if len(result.AuthorizationData) == 0 {
+		return nil, fmt.Errorf("ECR: response contains no authorization data")
+	}
+
+	data := result.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(data.AuthorizationToken)
+	if err != nil {
+		return nil, fmt.Errorf("ECR: invalid authorization token: %w", err)
+	}
+
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return nil, fmt.Errorf("ECR: invalid authorization token")
+	}
+
+	seconds, fraction := math.Modf(data.ExpiresAt)
+
+	return &ECRToken{
+		Username:      username,
+		Password:      password,
+		ProxyEndpoint: data.ProxyEndpoint,
+		ExpiresAt:     time.Unix(int64(seconds), int64(fraction*1e9)),
+	}, nil
+}
+
+// SignV4 signs the request with AWS Signature Version 4, all headers set on the request are signed
+func SignV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	dateStamp := now.Format(sigV4DateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	// canonical headers, the host is not part of req.Header
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignV4(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	assert.NoError(t, err)
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	SignV4(req, nil, AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "service", now)
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestGetECRAuthorizationToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "{}", string(body))
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, ecrTarget, r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+		assert.Contains(t, auth, "/eu-central-1/ecr/aws4_request")
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target")
+
+		token := base64.StdEncoding.EncodeToString([]byte("AWS:secret-token"))
+		w.Header().Set("Content-Type", ecrContentType)
+		_, _ = w.Write([]byte(`{"authorizationData":[{"authorizationToken":"` + token +
+			`","expiresAt":1.7000432E9,"proxyEndpoint":"https://123456789012.dkr.ecr.eu-central-1.amazonaws.com"}]}`))
+	}))
+	defer server.Close()
+
+	token, err := GetECRAuthorizationToken(context.Background(), server.Client(), server.URL, "eu-central-1",
+		AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"})
+	assert.NoError(t, err)
+	assert.Equal(t, "AWS", token.Username)
+	assert.Equal(t, "secret-token", token.Password)
+	assert.Equal(t, "https://123456789012.dkr.ecr.eu-central-1.amazonaws.com", token.ProxyEndpoint)
+	assert.Equal(t, int64(1700043200), token.ExpiresAt.Unix())
+}
+
+func TestGetECRAuthorizationTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"UnrecognizedClientException","message":"The security token included in the request is invalid."}`))
+	}))
+	defer server.Close()
+
+	_, err := GetECRAuthorizationToken(context.Background(), server.Client(), server.URL, "eu-central-1",
+		AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	assert.ErrorContains(t, err, "UnrecognizedClientException")
+
+	_, err = GetECRAuthorizationToken(context.Background(), server.Client(), server.URL, "",
+		AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	assert.Error(t, err)
+}
+
+func TestLoadAWSProfile(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+
+	assert.NoError(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# pipeline user
+[ci]
+aws_access_key_id=AKIDCI
+aws_secret_access_key=ci-secret
+aws_session_token=ci-session
+
+[sso]
+sso_session = company
+`), 0o600))
+	assert.NoError(t, os.WriteFile(configFile, []byte(`[default]
+region = eu-west-1
+
+[profile ci]
+region = us-east-2
+`), 0o600))
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	creds, region, err := LoadAWSProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "AKIDDEFAULT", SecretAccessKey: "default-secret"}, creds)
+	assert.Equal(t, "eu-west-1", region)
+
+	creds, region, err = LoadAWSProfile("ci")
+	assert.NoError(t, err)
+	assert.Equal(t, AWSCredentials{AccessKeyID: "AKIDCI", SecretAccessKey: "ci-secret", SessionToken: "ci-session"}, creds)
+	assert.Equal(t, "us-east-2", region)
+
+	_, _, err = LoadAWSProfile("sso")
+	assert.Error(t, err)
+
+	_, _, err = LoadAWSProfile("unknown")
+	assert.Error(t, err)
+}
+
+func TestParseECRHost(t *testing.T) {
+	account, region, ok := ParseECRHost("https://123456789012.dkr.ecr.eu-central-1.amazonaws.com")
+	assert.True(t, ok)
+	assert.Equal(t, "123456789012", account)
+	assert.Equal(t, "eu-central-1", region)
+
+	_, region, ok = ParseECRHost("123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn")
+	assert.True(t, ok)
+	assert.Equal(t, "cn-north-1", region)
+
+	_, _, ok = ParseECRHost("<account-id>.dkr.ecr.<region>.amazonaws.com")
+	assert.False(t, ok)
+	_, _, ok = ParseECRHost("ghcr.io")
+	assert.False(t, ok)
+}
+
+func TestECREndpoint(t *testing.T) {
+	assert.Equal(t, "https://api.ecr.eu-central-1.amazonaws.com", ECREndpoint("eu-central-1"))
+	assert.Equal(t, "https://api.ecr.cn-north-1.amazonaws.com.cn", ECREndpoint("cn-north-1"))
+	assert.Equal(t, "https://api.ecr.cn-northwest-1.amazonaws.com.cn", ECREndpoint("cn-northwest-1"))
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -30,11 +31,21 @@ func WriteFile(path string, content []byte) error {
 }
 
 func EnsureYAMLExt(path string) string {
+	return EnsureExt(path, ".yaml", ".yml")
+}
+
+// EnsureExt returns the path unchanged if it has one of the given extensions,
+// otherwise its extension is replaced with the first one
+func EnsureExt(path string, exts ...string) string {
+	if len(exts) == 0 {
+		return path
+	}
+
 	ext := strings.ToLower(filepath.Ext(path))
 
-	if ext == ".yaml" || ext == ".yml" {
+	if slices.Contains(exts, ext) {
 		return path
 	}
 
-	return strings.TrimSuffix(path, ext) + ".yaml"
+	return strings.TrimSuffix(path, filepath.Ext(path)) + exts[0]
 }
@@ -1,103 +1,413 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"slices"
 	"strings"
+	"time"
 )
 
-// MaxHistory defines the maximum number of entries to keep in history lists
-const MaxHistory = 100
+// DefaultMaxHistory defines the default maximum number of entries to keep in history lists
+const DefaultMaxHistory = 100
 
+// HistoryKind identifies one of the history lists
+type HistoryKind string
+
+const (
+	HistoryRegistries HistoryKind = "registries"
+	HistoryNames      HistoryKind = "names"
+	HistoryNamespaces HistoryKind = "namespaces"
+)
+
+// HistoryKinds lists the history lists in the order shown by the history manager
+var HistoryKinds = []HistoryKind{HistoryRegistries, HistoryNames, HistoryNamespaces}
+
+// HistoryOrder is the order of the history entries in the dropdowns, pinned entries are always first
+type HistoryOrder string
+
+const (
+	HistoryOrderRecent       HistoryOrder = "recent"
+	HistoryOrderMostUsed     HistoryOrder = "mostUsed"
+	HistoryOrderAlphabetical HistoryOrder = "alphabetical"
+)
+
+// HistoryOrderOption is a selectable order with its display name
+type HistoryOrderOption struct {
+	Order HistoryOrder
+	Name  string
+}
+
+// HistoryOrders lists the selectable orders
+var HistoryOrders = []HistoryOrderOption{
+	{Order: HistoryOrderRecent, Name: "Most recent"},
+	{Order: HistoryOrderMostUsed, Name: "Most used"},
+	{Order: HistoryOrderAlphabetical, Name: "Alphabetical"},
+}
+
+// HistoryEntry is one value of a history list with its usage
+type HistoryEntry struct {
+	Value    string    `json:"value" yaml:"value"`
+	LastUsed time.Time `json:"lastUsed,omitzero" yaml:"lastUsed,omitempty"`
+	Count    int       `json:"count" yaml:"count"`
+	Pinned   bool      `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+}
+
+// UnmarshalJSON also accepts a bare string, the entry format of older history exports
+func (e *HistoryEntry) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		*e = HistoryEntry{Count: 1}
+		return json.Unmarshal(data, &e.Value)
+	}
+
+	type entry HistoryEntry
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+// AppHistory keeps the lists most recently used first
 type AppHistory struct {
-	Names      []string
-	Namespaces []string
-	Registries []string
+	Names      []HistoryEntry `json:"names" yaml:"names"`
+	Namespaces []HistoryEntry `json:"namespaces" yaml:"namespaces"`
+	Registries []HistoryEntry `json:"registries" yaml:"registries"`
+	// combinations used together, most recently used first
+	Links []HistoryLink `json:"links,omitempty" yaml:"links,omitempty"`
+	// favorites of older history exports, moved to the entries on import
+	LegacyPinned map[HistoryKind][]string `json:"pinned,omitempty" yaml:"-"`
+
+	order      HistoryOrder
+	maxEntries int
+	now        func() time.Time
+	// history of the team defaults, only suggested after the own entries and never saved
+	team *AppHistory
 }
 
 func NewAppHistory() *AppHistory {
 	return &AppHistory{
-		Names:      []string{},
-		Namespaces: []string{},
-		Registries: []string{},
+		Names:      []HistoryEntry{},
+		Namespaces: []HistoryEntry{},
+		Registries: []HistoryEntry{},
+		order:      HistoryOrderRecent,
+		maxEntries: DefaultMaxHistory,
+		now:        time.Now,
 	}
 }
 
-// addValue adds value to the list if not exists, removes overflow
-func addValue(list []string, value string) []string {
-	// Only add if not exists and it's not empty
-	if strings.TrimSpace(value) == "" {
-		return list
+// NewLegacyHistoryEntries converts a list of bare values, oldest first, into entries most recently used first
+func NewLegacyHistoryEntries(values []string) []HistoryEntry {
+	entries := make([]HistoryEntry, 0, len(values))
+	for _, value := range slices.Backward(values) {
+		entries = append(entries, HistoryEntry{Value: value, Count: 1})
 	}
+	return entries
+}
 
-	if slices.Contains(list, value) {
-		return list
+// HistoryValues returns the values of the entries
+func HistoryValues(entries []HistoryEntry) []string {
+	values := make([]string, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, e.Value)
 	}
+	return values
+}
 
-	list = append(list, value)
+// use adds value to the front of the list or moves an existing entry there, removes overflow
+func (h *AppHistory) use(kind HistoryKind, value string) {
+	// Only add if it's not empty
+	if strings.TrimSpace(value) == "" {
+		return
+	}
 
-	// remove Overflow
-	if len(list) > MaxHistory {
-		list = list[1:]
+	list := h.list(kind)
+	entry := HistoryEntry{Value: value}
+	if i := slices.IndexFunc(*list, func(e HistoryEntry) bool { return e.Value == value }); i >= 0 {
+		entry = (*list)[i]
+		*list = slices.Delete(*list, i, i+1)
 	}
+	entry.Count++
+	entry.LastUsed = h.now()
 
-	return list
+	*list = slices.Insert(*list, 0, entry)
+	h.trim(kind)
+}
+
+// trim removes the least recently used entries which are not pinned until the list fits
+func (h *AppHistory) trim(kind HistoryKind) {
+	list := h.list(kind)
+	for i := len(*list) - 1; i >= 0 && len(*list) > h.maxEntries; i-- {
+		if !(*list)[i].Pinned {
+			*list = slices.Delete(*list, i, i+1)
+		}
+	}
 }
 
-func (h *AppHistory) SetNames(names []string) {
+func (h *AppHistory) SetNames(names []HistoryEntry) {
 	h.Names = names
 }
 
-func (h *AppHistory) SetNamespaces(namespaces []string) {
+func (h *AppHistory) SetNamespaces(namespaces []HistoryEntry) {
 	h.Namespaces = namespaces
 }
 
-func (h *AppHistory) SetRegistries(registries []string) {
+func (h *AppHistory) SetRegistries(registries []HistoryEntry) {
 	h.Registries = registries
 }
 
-// Add... methods add new entries to the history lists
+// SetOrder sets the order of the Sorted... methods, unknown orders fall back to the most recent
+func (h *AppHistory) SetOrder(order HistoryOrder) {
+	if !slices.ContainsFunc(HistoryOrders, func(o HistoryOrderOption) bool { return o.Order == order }) {
+		order = HistoryOrderRecent
+	}
+	h.order = order
+}
+
+func (h *AppHistory) Order() HistoryOrder {
+	return h.order
+}
+
+// SetMaxEntries sets the size of each list and removes the overflow, values below 1 use DefaultMaxHistory
+func (h *AppHistory) SetMaxEntries(n int) {
+	if n < 1 {
+		n = DefaultMaxHistory
+	}
+	h.maxEntries = n
+	for _, kind := range HistoryKinds {
+		h.trim(kind)
+	}
+	h.trimLinks()
+}
+
+func (h *AppHistory) MaxEntries() int {
+	return h.maxEntries
+}
+
+// Add... methods add new entries to the history lists or count the use of existing ones
 func (h *AppHistory) AddSecretName(name string) {
 	if IsK8sNameValid(name) {
-		h.Names = addValue(h.Names, name)
+		h.use(HistoryNames, name)
 	}
 }
 
 func (h *AppHistory) AddNamespace(namespace string) {
-	// Only add valid k8s names
-	if IsK8sNameValid(namespace) {
-		h.Namespaces = addValue(h.Namespaces, namespace)
+	// Only add valid k8s namespaces
+	if IsK8sNamespaceValid(namespace) {
+		h.use(HistoryNamespaces, namespace)
 	}
 }
 
 func (h *AppHistory) AddRegistry(registry string) {
-	h.Registries = addValue(h.Registries, registry)
+	h.use(HistoryRegistries, registry)
+}
+
+// isValidHistoryValue reports whether the value is accepted by the Add... method of the kind
+func isValidHistoryValue(kind HistoryKind, value string) bool {
+	switch kind {
+	case HistoryNames:
+		return IsK8sNameValid(value)
+	case HistoryNamespaces:
+		return IsK8sNamespaceValid(value)
+	default:
+		return strings.TrimSpace(value) != ""
+	}
+}
+
+// list returns the list of the given kind
+func (h *AppHistory) list(kind HistoryKind) *[]HistoryEntry {
+	switch kind {
+	case HistoryRegistries:
+		return &h.Registries
+	case HistoryNames:
+		return &h.Names
+	case HistoryNamespaces:
+		return &h.Namespaces
+	default:
+		panic(fmt.Sprintf("unknown history kind %q", kind))
+	}
+}
+
+// Entry returns the entry of a value
+func (h *AppHistory) Entry(kind HistoryKind, value string) (HistoryEntry, bool) {
+	if !slices.Contains(HistoryKinds, kind) {
+		return HistoryEntry{}, false
+	}
+	list := *h.list(kind)
+	if i := slices.IndexFunc(list, func(e HistoryEntry) bool { return e.Value == value }); i >= 0 {
+		return list[i], true
+	}
+	return HistoryEntry{}, false
+}
+
+// Remove deletes a single entry and the combinations it was used in
+func (h *AppHistory) Remove(kind HistoryKind, value string) {
+	list := h.list(kind)
+	*list = slices.DeleteFunc(*list, func(e HistoryEntry) bool { return e.Value == value })
+	h.removeLinks(kind, value)
+}
+
+// Pin marks an entry of the list as favorite or removes the mark, unknown kinds are ignored
+func (h *AppHistory) Pin(kind HistoryKind, value string, pinned bool) {
+	if !slices.Contains(HistoryKinds, kind) {
+		return
+	}
+	list := *h.list(kind)
+	if i := slices.IndexFunc(list, func(e HistoryEntry) bool { return e.Value == value }); i >= 0 {
+		list[i].Pinned = pinned
+	}
+}
+
+// IsPinned reports whether the entry is a favorite
+func (h *AppHistory) IsPinned(kind HistoryKind, value string) bool {
+	entry, ok := h.Entry(kind, value)
+	return ok && entry.Pinned
+}
+
+// Search returns the entries of the list containing the query case-insensitively, pinned entries first
+func (h *AppHistory) Search(kind HistoryKind, query string) []string {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var matches []string
+	for _, value := range h.sorted(kind) {
+		if strings.Contains(strings.ToLower(value), query) {
+			matches = append(matches, value)
+		}
+	}
+	return matches
+}
+
+// sorted returns the values of the list, pinned entries first and each part in the order of the history
+func (h *AppHistory) sorted(kind HistoryKind) []string {
+	entries := append([]HistoryEntry{}, *h.list(kind)...)
+	slices.SortStableFunc(entries, func(a, b HistoryEntry) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		switch h.order {
+		case HistoryOrderMostUsed:
+			if a.Count != b.Count {
+				return b.Count - a.Count
+			}
+			return b.LastUsed.Compare(a.LastUsed)
+		case HistoryOrderAlphabetical:
+			return strings.Compare(a.Value, b.Value)
+		default:
+			// the lists are kept most recently used first
+			return 0
+		}
+	})
+
+	return HistoryValues(entries)
 }
 
-// Sorted... methods return sorted copies of the history lists
+// suggested returns the sorted values of the list followed by the remaining ones of the team history
+func (h *AppHistory) suggested(kind HistoryKind) []string {
+	if h.team == nil {
+		return h.sorted(kind)
+	}
+	return MergeSuggestions(h.sorted(kind), h.team.sorted(kind))
+}
+
+// Sorted... methods return the values of the history lists in the chosen order, pinned entries first.
+// Values only the team history has follow the own ones.
 func (h *AppHistory) SortedNames() []string {
-	copyList := append([]string{}, h.Names...)
-	slices.Sort(copyList)
-	return copyList
+	return h.suggested(HistoryNames)
 }
 
 func (h *AppHistory) SortedNamespaces() []string {
-	copyList := append([]string{}, h.Namespaces...)
-	slices.Sort(copyList)
-	return copyList
+	return h.suggested(HistoryNamespaces)
 }
 
 func (h *AppHistory) SortedRegistries() []string {
-	copyList := append([]string{}, h.Registries...)
-	slices.Sort(copyList)
-	return copyList
+	return h.suggested(HistoryRegistries)
+}
+
+// SetTeam sets the history of the team defaults, nil for none. It is read-only: its values are suggested
+// after the own ones but can not be removed or pinned, and they are neither exported nor saved.
+func (h *AppHistory) SetTeam(team *AppHistory) {
+	h.team = team
 }
 
 func (h *AppHistory) Clear() {
-	h.Names = []string{}
-	h.Namespaces = []string{}
-	h.Registries = []string{}
+	h.Names = []HistoryEntry{}
+	h.Namespaces = []HistoryEntry{}
+	h.Registries = []HistoryEntry{}
+	h.Links = nil
 }
 
 func (h *AppHistory) IsEmpty() bool {
 	return len(h.Names) == 0 && len(h.Namespaces) == 0 && len(h.Registries) == 0
 }
+
+// ExportJSON returns all lists as JSON
+func (h *AppHistory) ExportJSON() ([]byte, error) {
+	return json.MarshalIndent(h, "", "  ")
+}
+
+// ImportJSON merges exported lists and combinations into the history, invalid entries are skipped like on input.
+// Existing entries keep the higher use count and the later use. It returns the number of added entries.
+func (h *AppHistory) ImportJSON(content []byte) (int, error) {
+	var imported AppHistory
+	if err := json.Unmarshal(content, &imported); err != nil {
+		return 0, fmt.Errorf("invalid history file: %w", err)
+	}
+	return h.Merge(&imported), nil
+}
+
+// Merge adds the entries and combinations of another history, e.g. of a shared settings file.
+// Invalid entries are skipped like on input, the number of new entries is returned.
+func (h *AppHistory) Merge(imported *AppHistory) int {
+	added := 0
+	for _, kind := range HistoryKinds {
+		list := h.list(kind)
+		for _, entry := range *imported.list(kind) {
+			entry.Value = strings.TrimSpace(entry.Value)
+			if !isValidHistoryValue(kind, entry.Value) {
+				continue
+			}
+			entry.Pinned = entry.Pinned || slices.Contains(imported.LegacyPinned[kind], entry.Value)
+
+			i := slices.IndexFunc(*list, func(e HistoryEntry) bool { return e.Value == entry.Value })
+			if i < 0 {
+				*list = append(*list, entry)
+				added++
+				continue
+			}
+
+			existing := &(*list)[i]
+			existing.Count = max(existing.Count, entry.Count)
+			if entry.LastUsed.After(existing.LastUsed) {
+				existing.LastUsed = entry.LastUsed
+			}
+			existing.Pinned = existing.Pinned || entry.Pinned
+		}
+
+		// keep the most recently used first, entries without time stay behind in their order
+		slices.SortStableFunc(*list, func(a, b HistoryEntry) int { return b.LastUsed.Compare(a.LastUsed) })
+		h.trim(kind)
+	}
+
+	for _, link := range imported.Links {
+		link.Registry = strings.TrimSpace(link.Registry)
+		if link.Registry == "" || (link.Name != "" && !IsK8sNameValid(link.Name)) ||
+			(link.Namespace != "" && !IsK8sNamespaceValid(link.Namespace)) {
+			continue
+		}
+
+		i := slices.IndexFunc(h.Links, link.same)
+		if i < 0 {
+			h.Links = append(h.Links, link)
+			continue
+		}
+		existing := &h.Links[i]
+		existing.Count = max(existing.Count, link.Count)
+		if link.LastUsed.After(existing.LastUsed) {
+			existing.LastUsed = link.LastUsed
+		}
+	}
+	slices.SortStableFunc(h.Links, func(a, b HistoryLink) int { return b.LastUsed.Compare(a.LastUsed) })
+	h.trimLinks()
+
+	return added
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestHistory returns a history whose clock advances one minute per use
+func newTestHistory() *AppHistory {
+	h := NewAppHistory()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return h
+}
+
+func TestAppHistory_PinAndRemove(t *testing.T) {
+	h := newTestHistory()
+	h.AddRegistry("quay.io")
+	h.AddRegistry("docker.io")
+	h.AddRegistry("ghcr.io")
+
+	h.Pin(HistoryRegistries, "quay.io", true)
+	h.Pin(HistoryRegistries, "unknown.io", true)
+	h.Pin("unknown", "quay.io", true)
+	assert.True(t, h.IsPinned(HistoryRegistries, "quay.io"))
+	assert.False(t, h.IsPinned(HistoryRegistries, "unknown.io"))
+	assert.Equal(t, []string{"quay.io", "ghcr.io", "docker.io"}, h.SortedRegistries())
+	assert.Equal(t, []string{"quay.io"}, h.Search(HistoryRegistries, " Q "))
+
+	h.Remove(HistoryRegistries, "quay.io")
+	assert.Equal(t, []string{"ghcr.io", "docker.io"}, h.SortedRegistries())
+	assert.False(t, h.IsPinned(HistoryRegistries, "quay.io"))
+}
+
+func TestAppHistory_Order(t *testing.T) {
+	h := newTestHistory()
+	h.AddNamespace("team-b")
+	h.AddNamespace("team-a")
+	h.AddNamespace("team-b")
+	h.AddNamespace("team-c")
+
+	entry, ok := h.Entry(HistoryNamespaces, "team-b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, entry.Count)
+
+	// reused entries move to the front
+	assert.Equal(t, []string{"team-c", "team-b", "team-a"}, h.SortedNamespaces())
+
+	h.SetOrder(HistoryOrderMostUsed)
+	assert.Equal(t, []string{"team-b", "team-c", "team-a"}, h.SortedNamespaces())
+
+	h.SetOrder(HistoryOrderAlphabetical)
+	assert.Equal(t, []string{"team-a", "team-b", "team-c"}, h.SortedNamespaces())
+
+	h.SetOrder("unknown")
+	assert.Equal(t, HistoryOrderRecent, h.Order())
+}
+
+func TestAppHistory_OverflowKeepsPinned(t *testing.T) {
+	h := newTestHistory()
+	for i := range DefaultMaxHistory {
+		h.AddSecretName(fmt.Sprintf("secret-%03d", i))
+	}
+	h.Pin(HistoryNames, "secret-000", true)
+
+	h.AddSecretName("secret-new")
+	assert.Len(t, h.Names, DefaultMaxHistory)
+	assert.True(t, h.IsPinned(HistoryNames, "secret-000"))
+	_, ok := h.Entry(HistoryNames, "secret-001")
+	assert.False(t, ok)
+
+	h.SetMaxEntries(3)
+	assert.Equal(t, []string{"secret-000", "secret-new", "secret-099"}, h.SortedNames())
+	assert.Equal(t, 3, h.MaxEntries())
+
+	h.SetMaxEntries(0)
+	assert.Equal(t, DefaultMaxHistory, h.MaxEntries())
+}
+
+func TestAppHistory_ExportImport(t *testing.T) {
+	h := newTestHistory()
+	h.AddRegistry("ghcr.io")
+	h.AddSecretName("ghcr-pull")
+	h.AddNamespace("team-a")
+	h.AddNamespace("team-a")
+	h.Pin(HistoryNamespaces, "team-a", true)
+
+	exported, err := h.ExportJSON()
+	assert.NoError(t, err)
+
+	imported := newTestHistory()
+	imported.AddRegistry("ghcr.io")
+	added, err := imported.ImportJSON(exported)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, []string{"ghcr-pull"}, imported.SortedNames())
+	entry, _ := imported.Entry(HistoryNamespaces, "team-a")
+	assert.Equal(t, 2, entry.Count)
+	assert.True(t, entry.Pinned)
+
+	// exports of older versions contain bare values and a separate pin list, invalid entries are skipped
+	added, err = imported.ImportJSON([]byte(`{"names": ["Invalid_Name", " valid-name "], "namespaces": ["a.b", "team-b"], "pinned": {"namespaces": ["team-b"]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.True(t, imported.IsPinned(HistoryNamespaces, "team-b"))
+	entry, _ = imported.Entry(HistoryNames, "valid-name")
+	assert.Equal(t, 1, entry.Count)
+
+	_, err = imported.ImportJSON([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestLoadHistory_Migration(t *testing.T) {
+	prefs := test.NewTempApp(t).Preferences()
+	prefs.SetStringList(PrefKeyRegistries, []string{"docker.io", "ghcr.io"})
+	prefs.SetStringList(PrefKeyNames, []string{"ghcr-pull"})
+	prefs.SetString(PrefKeyPinned, `{"registries": ["docker.io"]}`)
+
+	history := loadHistory(prefs)
+	// the old lists were oldest first
+	assert.Equal(t, []string{"ghcr.io", "docker.io"}, HistoryValues(history.Registries))
+	assert.True(t, history.IsPinned(HistoryRegistries, "docker.io"))
+	assert.Equal(t, []string{"ghcr-pull"}, history.SortedNames())
+
+	history.AddNamespace("team-a")
+	saveHistory(prefs, history)
+	assert.Empty(t, prefs.StringList(PrefKeyRegistries))
+	assert.Empty(t, prefs.String(PrefKeyPinned))
+
+	reloaded := loadHistory(prefs)
+	assert.Equal(t, history.Registries, reloaded.Registries)
+	assert.Equal(t, []string{"team-a"}, reloaded.SortedNamespaces())
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// HistoryLink records which registry, username, secret name and namespace were used together.
+// Passwords are never part of the history.
+type HistoryLink struct {
+	Registry  string    `json:"registry" yaml:"registry"`
+	Username  string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Name      string    `json:"name,omitempty" yaml:"name,omitempty"`
+	Namespace string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	LastUsed  time.Time `json:"lastUsed,omitzero" yaml:"lastUsed,omitempty"`
+	Count     int       `json:"count" yaml:"count"`
+}
+
+// same reports whether both links are the same combination
+func (l HistoryLink) same(other HistoryLink) bool {
+	return l.Registry == other.Registry && l.Username == other.Username &&
+		l.Name == other.Name && l.Namespace == other.Namespace
+}
+
+// AddLink records a combination of values used for one secret, invalid names and namespaces are left out
+func (h *AppHistory) AddLink(registry, username, name, namespace string) {
+	link := HistoryLink{
+		Registry: strings.TrimSpace(registry),
+		Username: strings.TrimSpace(username),
+	}
+	if link.Registry == "" {
+		return
+	}
+	if name = strings.TrimSpace(name); IsK8sNameValid(name) {
+		link.Name = name
+	}
+	if namespace = strings.TrimSpace(namespace); IsK8sNamespaceValid(namespace) {
+		link.Namespace = namespace
+	}
+
+	if i := slices.IndexFunc(h.Links, link.same); i >= 0 {
+		link = h.Links[i]
+		h.Links = slices.Delete(h.Links, i, i+1)
+	}
+	link.Count++
+	link.LastUsed = h.now()
+
+	h.Links = slices.Insert(h.Links, 0, link)
+	h.trimLinks()
+}
+
+// trimLinks removes the least recently used combinations, every list entry may have a few of them
+func (h *AppHistory) trimLinks() {
+	if limit := h.maxEntries * len(HistoryKinds); len(h.Links) > limit {
+		h.Links = h.Links[:limit]
+	}
+}
+
+// RegistryUsernames returns the usernames used with the registry, most recently used first
+func (h *AppHistory) RegistryUsernames(registry string) []string {
+	return h.linkedValues(func(l HistoryLink) string {
+		if l.Registry != strings.TrimSpace(registry) {
+			return ""
+		}
+		return l.Username
+	})
+}
+
+// RegistryNamespaces returns the namespaces used with the registry, most recently used first
+func (h *AppHistory) RegistryNamespaces(registry string) []string {
+	return h.linkedValues(func(l HistoryLink) string {
+		if l.Registry != strings.TrimSpace(registry) {
+			return ""
+		}
+		return l.Namespace
+	})
+}
+
+// RegistryNames returns the secret names used with the registry, most recently used first
+func (h *AppHistory) RegistryNames(registry string) []string {
+	return h.linkedValues(func(l HistoryLink) string {
+		if l.Registry != strings.TrimSpace(registry) {
+			return ""
+		}
+		return l.Name
+	})
+}
+
+// NamespaceNames returns the secret names used in the namespace, most recently used first.
+// Names used together with the registry come first if a registry is given.
+func (h *AppHistory) NamespaceNames(namespace, registry string) []string {
+	namespace, registry = strings.TrimSpace(namespace), strings.TrimSpace(registry)
+
+	withRegistry := h.linkedValues(func(l HistoryLink) string {
+		if l.Namespace != namespace || l.Registry != registry {
+			return ""
+		}
+		return l.Name
+	})
+	inNamespace := h.linkedValues(func(l HistoryLink) string {
+		if l.Namespace != namespace {
+			return ""
+		}
+		return l.Name
+	})
+	return MergeSuggestions(withRegistry, inNamespace)
+}
+
+// linkedValues returns the distinct non-empty values of all links in their order, the own links before
+// the ones of the team history
+func (h *AppHistory) linkedValues(value func(HistoryLink) string) []string {
+	links := h.Links
+	if h.team != nil {
+		links = slices.Concat(h.Links, h.team.Links)
+	}
+
+	var values []string
+	for _, l := range links {
+		if v := value(l); v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// removeLinks deletes the combinations containing a removed history entry
+func (h *AppHistory) removeLinks(kind HistoryKind, value string) {
+	h.Links = slices.DeleteFunc(h.Links, func(l HistoryLink) bool {
+		switch kind {
+		case HistoryRegistries:
+			return l.Registry == value
+		case HistoryNames:
+			return l.Name == value
+		case HistoryNamespaces:
+			return l.Namespace == value
+		default:
+			return false
+		}
+	})
+}
+
+// MergeSuggestions returns the suggestions first followed by the remaining values, without duplicates
+func MergeSuggestions(suggestions, values []string) []string {
+	merged := append([]string{}, suggestions...)
+	for _, v := range values {
+		if !slices.Contains(merged, v) {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
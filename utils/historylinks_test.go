@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppHistory_Links(t *testing.T) {
+	h := newTestHistory()
+	h.AddLink("ghcr.io", "alice", "ghcr-pull", "team-a")
+	h.AddLink("ghcr.io", "bob", "ghcr-bot", "team-b")
+	h.AddLink("quay.io", "carol", "quay-pull", "team-a")
+	h.AddLink(" ghcr.io ", "alice", "ghcr-pull", "team-a")
+	// invalid metadata is left out, an empty registry is ignored
+	h.AddLink("ghcr.io", "dave", "Invalid_Name", "")
+	h.AddLink("", "eve", "eve-pull", "team-a")
+
+	assert.Len(t, h.Links, 4)
+	assert.Equal(t, 2, h.Links[1].Count)
+	assert.Equal(t, []string{"dave", "alice", "bob"}, h.RegistryUsernames("ghcr.io"))
+	assert.Equal(t, []string{"team-a", "team-b"}, h.RegistryNamespaces("ghcr.io"))
+	assert.Equal(t, []string{"ghcr-pull", "ghcr-bot"}, h.RegistryNames("ghcr.io"))
+	assert.Empty(t, h.RegistryUsernames("docker.io"))
+
+	// names used with the registry come first
+	assert.Equal(t, []string{"quay-pull", "ghcr-pull"}, h.NamespaceNames("team-a", "quay.io"))
+	assert.Equal(t, []string{"ghcr-pull", "quay-pull"}, h.NamespaceNames("team-a", "ghcr.io"))
+
+	h.Remove(HistoryNamespaces, "team-a")
+	assert.Equal(t, []string{"dave", "bob"}, h.RegistryUsernames("ghcr.io"))
+	assert.Empty(t, h.NamespaceNames("team-a", ""))
+
+	h.Clear()
+	assert.Empty(t, h.Links)
+}
+
+func TestAppHistory_LinksLimit(t *testing.T) {
+	h := newTestHistory()
+	h.SetMaxEntries(1)
+	for _, user := range []string{"a", "b", "c", "d", "e"} {
+		h.AddLink("ghcr.io", user, "", "")
+	}
+	assert.Equal(t, []string{"e", "d", "c"}, h.RegistryUsernames("ghcr.io"))
+}
+
+func TestAppHistory_LinksExportImport(t *testing.T) {
+	h := newTestHistory()
+	h.AddLink("ghcr.io", "alice", "ghcr-pull", "team-a")
+	h.AddLink("ghcr.io", "alice", "ghcr-pull", "team-a")
+
+	exported, err := h.ExportJSON()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(exported), "password")
+
+	imported := newTestHistory()
+	imported.AddLink("ghcr.io", "alice", "ghcr-pull", "team-a")
+	imported.AddLink("quay.io", "bob", "", "")
+	_, err = imported.ImportJSON(exported)
+	assert.NoError(t, err)
+	assert.Len(t, imported.Links, 2)
+	assert.Equal(t, 2, imported.Links[1].Count)
+	assert.Equal(t, []string{"alice"}, imported.RegistryUsernames("ghcr.io"))
+}
+
+func TestMergeSuggestions(t *testing.T) {
+	assert.Equal(t, []string{"b", "a", "c"}, MergeSuggestions([]string{"b"}, []string{"a", "b", "c"}))
+	assert.Empty(t, MergeSuggestions(nil, nil))
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -51,7 +52,8 @@ func TestIsK8sNameValid_Negative(t *testing.T) {
 		"-starts-dash",        // begins with dash
 		"ends-dash-",          // ends with dash
 		"contains_underscore", // _ not allowed
-		"contains.dot",        // . not allowed
+		"dot.-dash",           // parts must start alphanumeric
+		"double..dot",         // empty part
 		"contains$money",      // $ not allowed
 		"contains!bang",       // ! not allowed
 		"contains@at",         // @ not allowed
@@ -76,9 +78,30 @@ func TestIsK8sNameValid_Positive(t *testing.T) {
 		"pullsecret-john-a3f2", // Docker-like Name
 		"my-secret-123",        // normal
 		"n1-n2-n3",             // multiple dashes
+		"my.secret",            // dots are allowed in subdomains
+		"registry.example.com", // host-like name
 	}
 
 	for _, name := range validNames {
 		assert.True(t, IsK8sNameValid(name), "Name should be valid: %s", name)
 	}
 }
+
+func TestIsK8sNamespaceValid(t *testing.T) {
+	for _, namespace := range []string{"default", "team-a", "a", strings.Repeat("n", 63)} {
+		assert.True(t, IsK8sNamespaceValid(namespace), "Namespace should be valid: %s", namespace)
+	}
+	for _, namespace := range []string{"", "my.namespace", "-team", "Team", strings.Repeat("n", 64), strings.Repeat("n", 100)} {
+		assert.False(t, IsK8sNamespaceValid(namespace), "Namespace should be invalid: %s", namespace)
+	}
+}
+
+func TestIsSecretKeyValid(t *testing.T) {
+	for _, key := range []string{"config.json", ".dockerconfigjson", "my_key-1"} {
+		assert.True(t, IsSecretKeyValid(key), "Key should be valid: %s", key)
+	}
+
+	for _, key := range []string{"", "with/slash", "with space", "ä", ".", "..", "..data"} {
+		assert.False(t, IsSecretKeyValid(key), "Key should be invalid: %s", key)
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseNamespaces splits a list of namespaces separated by commas or whitespace, duplicates are removed
+func ParseNamespaces(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	})
+
+	namespaces := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !slices.Contains(namespaces, field) {
+			namespaces = append(namespaces, field)
+		}
+	}
+	return namespaces
+}
+
+// JoinNamespaces is the inverse of ParseNamespaces
+func JoinNamespaces(namespaces []string) string {
+	return strings.Join(namespaces, ", ")
+}
+
+// KubeconfigNamespaces returns the sorted namespaces of all contexts in the kubeconfig files
+// of $KUBECONFIG or ~/.kube/config, files which can not be read are skipped
+func KubeconfigNamespaces() []string {
+	var paths []string
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		paths = filepath.SplitList(env)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		paths = []string{filepath.Join(home, ".kube", "config")}
+	}
+
+	var namespaces []string
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var kubeconfig struct {
+			Contexts []struct {
+				Context struct {
+					Namespace string `yaml:"namespace"`
+				} `yaml:"context"`
+			} `yaml:"contexts"`
+		}
+		if err := yaml.Unmarshal(content, &kubeconfig); err != nil {
+			continue
+		}
+
+		for _, ctx := range kubeconfig.Contexts {
+			namespace := ctx.Context.Namespace
+			if namespace != "" && !slices.Contains(namespaces, namespace) {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+
+	slices.Sort(namespaces)
+	return namespaces
+}
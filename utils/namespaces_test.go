@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNamespaces(t *testing.T) {
+	assert.Equal(t, []string{"team-a", "team-b", "team-c"}, ParseNamespaces(" team-a, team-b;team-c  team-a,"))
+	assert.Empty(t, ParseNamespaces(" , "))
+	assert.Equal(t, "team-a, team-b", JoinNamespaces([]string{"team-a", "team-b"}))
+}
+
+func TestKubeconfigNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+
+	assert.NoError(t, os.WriteFile(first, []byte(`apiVersion: v1
+kind: Config
+contexts:
+  - name: dev
+    context:
+      cluster: dev
+      namespace: team-b
+  - name: admin
+    context:
+      cluster: dev
+`), 0o600))
+	assert.NoError(t, os.WriteFile(second, []byte(`contexts:
+  - name: prod
+    context:
+      namespace: team-a
+  - name: prod-b
+    context:
+      namespace: team-b
+`), 0o600))
+
+	t.Setenv("KUBECONFIG", first+string(os.PathListSeparator)+second+string(os.PathListSeparator)+filepath.Join(dir, "missing"))
+	assert.Equal(t, []string{"team-a", "team-b"}, KubeconfigNamespaces())
+}
@@ -5,7 +5,41 @@ const (
 	PrefKeyIsLightTheme = "isLightTheme"
 	PrefKeyWindowWidth  = "windowWidth"
 	PrefKeyWindowHeight = "windowHeight"
-	PrefKeyRegistries   = "registries"
-	PrefKeyNamespaces   = "namespaces"
-	PrefKeyNames        = "names"
+	PrefKeyHistory      = "history"
+	PrefKeyHistoryOrder = "historyOrder"
+	PrefKeyMaxHistory   = "maxHistory"
+	PrefKeyFormatOpts   = "formatOptions"
+	PrefKeyCredBackend  = "credentialBackend"
+)
+
+// SettingsVersion is the version of the keys below, keys of older versions are migrated on load
+const SettingsVersion = 1
+
+// keys of the settings window, versioned to migrate them when their meaning changes
+const (
+	PrefKeySettingsVersion  = "settingsVersion"
+	PrefKeyNameTemplate     = "v1.nameTemplate"
+	PrefKeyDefaultNamespace = "v1.defaultNamespace"
+	PrefKeyDefaultFormat    = "v1.defaultFormat"
+	PrefKeyYAMLIndent       = "v1.yamlIndent"
+	PrefKeyStringData       = "v1.stringData"
+	PrefKeyToastSeconds     = "v1.toastSeconds"
+	PrefKeyCopyOnGenerate   = "v1.copyOnGenerate"
+	PrefKeyClipboardClear   = "v1.clipboardClearSeconds"
+	PrefKeyTeamDefaults     = "v1.teamDefaultsPath"
+	PrefKeyDefaultLabels    = "v1.defaultLabels"
+	PrefKeyPolicy           = "v1.policyPath"
+)
+
+// history keys before the history entries had usage data, migrated on the first start
+const (
+	PrefKeyRegistries = "registries"
+	PrefKeyNamespaces = "namespaces"
+	PrefKeyNames      = "names"
+	PrefKeyPinned     = "pinnedHistory"
+)
+
+// keys before the settings were versioned, migrated on the first start
+const (
+	PrefKeyNameTemplateV0 = "nameTemplate"
 )
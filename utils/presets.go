@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/javaLux/registrymate/pkg/pullsecret"
+)
+
+// RegistryPreset describes the quirks of a well-known registry provider
+type RegistryPreset struct {
+	Name string
+	// registry host, placeholders in angle brackets have to be replaced by the user
+	Host string
+	// fixed username, if the provider expects one
+	Username string
+	// provider specific hint shown below the preset selector
+	Hint string
+	// checks the username format, nil if every username is accepted
+	CheckUsername func(username string) error
+	// reads a key file and returns the password for the username, nil if the provider uses no key files
+	ReadKeyFile func(content []byte, username string) (string, error)
+	// the password is a short-lived token which can be fetched from the provider API
+	FetchToken bool
+}
+
+// PresetCustom is the preset without any provider specific behavior
+const PresetCustom = "Custom"
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// RegistryPresets returns all known provider presets, the first one is the custom preset
+func RegistryPresets() []RegistryPreset {
+	return []RegistryPreset{
+		{
+			Name: PresetCustom,
+		},
+		{
+			Name: "Docker Hub",
+			Host: "https://index.docker.io/v1/",
+			Hint: "Use your Docker ID as username and a personal access token as password.",
+		},
+		{
+			Name: "GitHub (GHCR)",
+			Host: "ghcr.io",
+			Hint: "Use your GitHub username and a personal access token (classic) with the read:packages scope as password.",
+		},
+		{
+			Name: "GitLab",
+			Host: "registry.gitlab.com",
+			Hint: "Use a deploy token with the read_registry scope, its username starts with gitlab+deploy-token-.",
+		},
+		{
+			Name:          "Google Container Registry",
+			Host:          "gcr.io",
+			Username:      "_json_key",
+			Hint:          "The username is _json_key, load the JSON key file of a service account as password. With _json_key_base64 the key is loaded base64 encoded.",
+			CheckUsername: checkGoogleUsername,
+			ReadKeyFile:   readGoogleKeyFile,
+		},
+		{
+			Name:          "Google Artifact Registry",
+			Host:          "<region>-docker.pkg.dev",
+			Username:      "_json_key",
+			Hint:          "Replace <region>, e.g. europe-west1. The username is _json_key, load the JSON key file of a service account as password. With _json_key_base64 the key is loaded base64 encoded.",
+			CheckUsername: checkGoogleUsername,
+			ReadKeyFile:   readGoogleKeyFile,
+		},
+		{
+			Name:          "Amazon ECR",
+			Host:          "<account-id>.dkr.ecr.<region>.amazonaws.com",
+			Username:      ECRUsername,
+			Hint:          "Replace <account-id> and <region>. The username is AWS, the password is a token of aws ecr get-login-password which expires after 12 hours. Fetch it with your AWS credentials via the download button.",
+			CheckUsername: checkFixedUsername(ECRUsername),
+			FetchToken:    true,
+		},
+		{
+			Name:          "Azure ACR",
+			Host:          "<registry>.azurecr.io",
+			Hint:          "Replace <registry>. Use the application (client) ID of a service principal as username and its client secret as password.",
+			CheckUsername: checkAzureUsername,
+		},
+		{
+			Name:          "Harbor",
+			Host:          "<harbor-host>",
+			Hint:          "Replace <harbor-host>. Use a robot account, its name starts with robot$ and is written exactly as shown in Harbor.",
+			CheckUsername: checkHarborUsername,
+		},
+		{
+			Name: "Quay.io",
+			Host: "quay.io",
+			Hint: "Use a robot account, its username has the format <namespace>+<robot-name>.",
+			CheckUsername: func(username string) error {
+				if !strings.Contains(username, "+") {
+					return fmt.Errorf("robot account usernames have the format <namespace>+<robot-name>")
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// FindRegistryPreset returns the preset with the given name
+func FindRegistryPreset(name string) (RegistryPreset, bool) {
+	for _, preset := range RegistryPresets() {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return RegistryPreset{}, false
+}
+
+// HasPlaceholder reports whether the registry host still contains a placeholder like <region>
+func HasPlaceholder(host string) bool {
+	return pullsecret.HasPlaceholder(host)
+}
+
+func checkFixedUsername(expected string) func(string) error {
+	return func(username string) error {
+		if username != expected {
+			return fmt.Errorf("the username must be %s", expected)
+		}
+		return nil
+	}
+}
+
+func checkGoogleUsername(username string) error {
+	if username != "_json_key" && username != "_json_key_base64" {
+		return fmt.Errorf("the username must be _json_key or _json_key_base64")
+	}
+	return nil
+}
+
+func checkAzureUsername(username string) error {
+	if !uuidRegex.MatchString(username) {
+		return fmt.Errorf("the username must be the client ID (UUID) of a service principal")
+	}
+	return nil
+}
+
+func checkHarborUsername(username string) error {
+	if !strings.HasPrefix(username, "robot$") {
+		return fmt.Errorf("robot account usernames start with robot$")
+	}
+	return nil
+}
+
+// readGoogleKeyFile validates a service account key file and returns it as compact JSON,
+// base64 encoded for the username _json_key_base64
+func readGoogleKeyFile(content []byte, username string) (string, error) {
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(content, &key); err != nil {
+		return "", fmt.Errorf("invalid JSON key file: %w", err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return "", fmt.Errorf("the file is no service account key")
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, content); err != nil {
+		return "", err
+	}
+
+	if username == "_json_key_base64" {
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,320 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// freedesktop Secret Service API, see https://specifications.freedesktop.org/secret-service-spec/latest/
+const (
+	secretServiceBusName  = "org.freedesktop.secrets"
+	secretServicePath     = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceIface    = "org.freedesktop.Secret.Service"
+	secretCollectionIface = "org.freedesktop.Secret.Collection"
+	secretItemIface       = "org.freedesktop.Secret.Item"
+	secretPromptIface     = "org.freedesktop.Secret.Prompt"
+	secretSessionIface    = "org.freedesktop.Secret.Session"
+	// no prompt is needed if a method returns this path
+	secretNoPrompt = dbus.ObjectPath("/")
+
+	// item attributes, everything except the password is stored as searchable attribute
+	secretAttrApplication = "application"
+	secretAttrProfile     = "profile"
+	secretAttrRegistry    = "registry"
+	secretAttrUsername    = "username"
+	secretAttrSecretName  = "secret-name"
+	secretAttrNamespace   = "namespace"
+	secretApplication     = "registrymate"
+
+	// the user has to answer unlock prompts of the keyring within this time
+	secretPromptTimeout = 2 * time.Minute
+)
+
+// secretServiceSecret is the Secret struct (oayays) of the API
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// SecretServiceStore keeps the profiles in the desktop keyring, e.g. GNOME Keyring or KWallet.
+// The password is the secret of the item, the other profile values are item attributes.
+type SecretServiceStore struct {
+	conn     *dbus.Conn
+	ownsConn bool
+	// plain session, the secrets are only transferred over the local session bus
+	session dbus.ObjectPath
+}
+
+// ConnectSecretService opens a store on the session bus, ErrSecretServiceUnavailable is returned
+// if there is no session bus or no Secret Service
+func ConnectSecretService() (CredentialStore, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSecretServiceUnavailable, err)
+	}
+
+	store, err := NewSecretServiceStore(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	store.ownsConn = true
+	return store, nil
+}
+
+// NewSecretServiceStore opens a store on an existing bus connection
+func NewSecretServiceStore(conn *dbus.Conn) (*SecretServiceStore, error) {
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err := conn.Object(secretServiceBusName, secretServicePath).
+		Call(secretServiceIface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSecretServiceUnavailable, err)
+	}
+
+	return &SecretServiceStore{conn: conn, session: session}, nil
+}
+
+func (s *SecretServiceStore) Name() string {
+	return CredentialBackendName(CredentialBackendSecretService)
+}
+
+func (s *SecretServiceStore) Profiles() ([]Profile, error) {
+	items, err := s.searchItems(map[string]string{secretAttrApplication: secretApplication})
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	var secrets map[dbus.ObjectPath]secretServiceSecret
+	if err := s.service().Call(secretServiceIface+".GetSecrets", 0, items, s.session).Store(&secrets); err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	profiles := make([]Profile, 0, len(items))
+	for _, item := range items {
+		secret, ok := secrets[item]
+		if !ok {
+			continue
+		}
+
+		attributes, err := s.itemAttributes(item)
+		if err != nil {
+			return nil, err
+		}
+
+		profiles = append(profiles, Profile{
+			Name:      attributes[secretAttrProfile],
+			Registry:  attributes[secretAttrRegistry],
+			Username:  attributes[secretAttrUsername],
+			Password:  string(secret.Value),
+			Secret:    attributes[secretAttrSecretName],
+			Namespace: attributes[secretAttrNamespace],
+		})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+func (s *SecretServiceStore) SaveProfile(p Profile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("profile name must not be empty")
+	}
+
+	var collection dbus.ObjectPath
+	if err := s.service().Call(secretServiceIface+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		return fmt.Errorf("failed to find the default keyring: %w", err)
+	}
+	if collection == secretNoPrompt {
+		return errors.New("there is no default keyring")
+	}
+	if _, err := s.unlock([]dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		secretItemIface + ".Label": dbus.MakeVariant(fmt.Sprintf("RegistryMate: %s", p.Name)),
+		secretItemIface + ".Attributes": dbus.MakeVariant(map[string]string{
+			secretAttrApplication: secretApplication,
+			secretAttrProfile:     p.Name,
+			secretAttrRegistry:    p.Registry,
+			secretAttrUsername:    p.Username,
+			secretAttrSecretName:  p.Secret,
+			secretAttrNamespace:   p.Namespace,
+		}),
+	}
+	secret := secretServiceSecret{
+		Session:     s.session,
+		Parameters:  []byte{},
+		Value:       []byte(p.Password),
+		ContentType: "text/plain",
+	}
+
+	var item, prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceBusName, collection).
+		Call(secretCollectionIface+".CreateItem", 0, properties, secret, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return fmt.Errorf("failed to save profile %s: %w", p.Name, err)
+	}
+	if prompt != secretNoPrompt {
+		result, err := s.prompt(prompt)
+		if err != nil {
+			return err
+		}
+		if path, ok := result.Value().(dbus.ObjectPath); ok {
+			item = path
+		}
+	}
+
+	// replace only matches equal attributes, remove the item of the profile before its last change
+	return s.deleteItems(p.Name, item)
+}
+
+func (s *SecretServiceStore) DeleteProfile(name string) error {
+	return s.deleteItems(name, "")
+}
+
+func (s *SecretServiceStore) Close() error {
+	err := s.conn.Object(secretServiceBusName, s.session).Call(secretSessionIface+".Close", 0).Err
+	if s.ownsConn {
+		err = errors.Join(err, s.conn.Close())
+	}
+	return err
+}
+
+func (s *SecretServiceStore) service() dbus.BusObject {
+	return s.conn.Object(secretServiceBusName, secretServicePath)
+}
+
+// searchItems returns the unlocked and the newly unlocked items matching the attributes
+func (s *SecretServiceStore) searchItems(attributes map[string]string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.service().Call(secretServiceIface+".SearchItems", 0, attributes).Store(&unlocked, &locked); err != nil {
+		return nil, fmt.Errorf("failed to search the keyring: %w", err)
+	}
+
+	if len(locked) > 0 {
+		newlyUnlocked, err := s.unlock(locked)
+		if err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, newlyUnlocked...)
+	}
+	return unlocked, nil
+}
+
+// deleteItems removes all items of the profile except keep
+func (s *SecretServiceStore) deleteItems(name string, keep dbus.ObjectPath) error {
+	items, err := s.searchItems(map[string]string{
+		secretAttrApplication: secretApplication,
+		secretAttrProfile:     name,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item == keep {
+			continue
+		}
+
+		var prompt dbus.ObjectPath
+		if err := s.conn.Object(secretServiceBusName, item).Call(secretItemIface+".Delete", 0).Store(&prompt); err != nil {
+			return fmt.Errorf("failed to delete profile %s: %w", name, err)
+		}
+		if prompt != secretNoPrompt {
+			if _, err := s.prompt(prompt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SecretServiceStore) itemAttributes(item dbus.ObjectPath) (map[string]string, error) {
+	variant, err := s.conn.Object(secretServiceBusName, item).GetProperty(secretItemIface + ".Attributes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring item %s: %w", item, err)
+	}
+
+	var attributes map[string]string
+	if err := variant.Store(&attributes); err != nil {
+		return nil, fmt.Errorf("failed to read keyring item %s: %w", item, err)
+	}
+	return attributes, nil
+}
+
+// unlock unlocks the objects, the keyring may ask the user for its password
+func (s *SecretServiceStore) unlock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, error) {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.service().Call(secretServiceIface+".Unlock", 0, objects).Store(&unlocked, &prompt); err != nil {
+		return nil, fmt.Errorf("failed to unlock the keyring: %w", err)
+	}
+	if prompt == secretNoPrompt {
+		return unlocked, nil
+	}
+
+	result, err := s.prompt(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var promptUnlocked []dbus.ObjectPath
+	if err := result.Store(&promptUnlocked); err != nil {
+		return nil, fmt.Errorf("failed to unlock the keyring: %w", err)
+	}
+	return append(unlocked, promptUnlocked...), nil
+}
+
+// prompt shows the prompt of the keyring and waits for its Completed signal
+func (s *SecretServiceStore) prompt(path dbus.ObjectPath) (dbus.Variant, error) {
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(secretPromptIface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(matchOptions...); err != nil {
+		return dbus.Variant{}, err
+	}
+	defer func() {
+		_ = s.conn.RemoveMatchSignal(matchOptions...)
+	}()
+
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretServiceBusName, path).Call(secretPromptIface+".Prompt", 0, "").Err; err != nil {
+		return dbus.Variant{}, fmt.Errorf("failed to show the keyring prompt: %w", err)
+	}
+
+	timeout := time.After(secretPromptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != path || signal.Name != secretPromptIface+".Completed" || len(signal.Body) != 2 {
+				continue
+			}
+			if dismissed, _ := signal.Body[0].(bool); dismissed {
+				return dbus.Variant{}, errors.New("the keyring prompt was dismissed")
+			}
+			result, _ := signal.Body[1].(dbus.Variant)
+			return result, nil
+		case <-timeout:
+			return dbus.Variant{}, errors.New("the keyring prompt timed out")
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !(linux || freebsd || openbsd || netbsd)
+
+package utils
+
+// ConnectSecretService returns ErrSecretServiceUnavailable, the Secret Service exists on Linux and BSD only
+func ConnectSecretService() (CredentialStore, error) {
+	return nil, ErrSecretServiceUnavailable
+}
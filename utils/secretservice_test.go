@@ -0,0 +1,304 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	fakeCollectionPath = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
+	fakeSessionPath    = dbus.ObjectPath("/org/freedesktop/secrets/session/1")
+	fakePromptPath     = dbus.ObjectPath("/org/freedesktop/secrets/prompt/1")
+)
+
+// startSessionBus starts a private dbus-daemon as stand-in for the session bus and returns its address
+func startSessionBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "session.conf")
+	assert.NoError(t, os.WriteFile(config, fmt.Appendf(nil, `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`, filepath.Join(dir, "bus")), 0o600))
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Skipf("dbus-daemon did not print its address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// fakeSecretService implements the parts of the Secret Service API used by SecretServiceStore.
+// The collection is locked until the first prompt is answered.
+type fakeSecretService struct {
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	locked  bool
+	pending []dbus.ObjectPath
+	items   map[dbus.ObjectPath]*fakeItem
+	nextID  int
+	prompts int
+}
+
+type fakeItem struct {
+	service    *fakeSecretService
+	path       dbus.ObjectPath
+	attributes map[string]string
+	secret     []byte
+}
+
+type fakeCollection struct{ service *fakeSecretService }
+
+type fakePrompt struct{ service *fakeSecretService }
+
+type fakeSession struct{}
+
+type fakeItemProperties struct{ item *fakeItem }
+
+func newFakeSecretService(t *testing.T, address string) *fakeSecretService {
+	t.Helper()
+
+	conn, err := dbus.Connect(address)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	f := &fakeSecretService{conn: conn, locked: true, items: map[dbus.ObjectPath]*fakeItem{}}
+	assert.NoError(t, conn.Export(f, secretServicePath, secretServiceIface))
+	assert.NoError(t, conn.Export(&fakeCollection{service: f}, fakeCollectionPath, secretCollectionIface))
+	assert.NoError(t, conn.Export(&fakePrompt{service: f}, fakePromptPath, secretPromptIface))
+	assert.NoError(t, conn.Export(fakeSession{}, fakeSessionPath, secretSessionIface))
+
+	reply, err := conn.RequestName(secretServiceBusName, dbus.NameFlagDoNotQueue)
+	assert.NoError(t, err)
+	assert.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+	return f
+}
+
+func (f *fakeSecretService) OpenSession(algorithm string, _ dbus.Variant) (dbus.Variant, dbus.ObjectPath, *dbus.Error) {
+	if algorithm != "plain" {
+		return dbus.Variant{}, "", dbus.MakeFailedError(fmt.Errorf("unsupported algorithm %s", algorithm))
+	}
+	return dbus.MakeVariant(""), fakeSessionPath, nil
+}
+
+func (f *fakeSecretService) ReadAlias(name string) (dbus.ObjectPath, *dbus.Error) {
+	if name == "default" {
+		return fakeCollectionPath, nil
+	}
+	return secretNoPrompt, nil
+}
+
+func (f *fakeSecretService) SearchItems(attributes map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matches []dbus.ObjectPath
+	for path, item := range f.items {
+		if matchAttributes(item.attributes, attributes) {
+			matches = append(matches, path)
+		}
+	}
+	if f.locked {
+		return []dbus.ObjectPath{}, matches, nil
+	}
+	return matches, []dbus.ObjectPath{}, nil
+}
+
+func (f *fakeSecretService) Unlock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.locked {
+		f.pending = objects
+		return []dbus.ObjectPath{}, fakePromptPath, nil
+	}
+	return objects, secretNoPrompt, nil
+}
+
+func (f *fakeSecretService) GetSecrets(items []dbus.ObjectPath, session dbus.ObjectPath) (map[dbus.ObjectPath]secretServiceSecret, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.locked {
+		return nil, dbus.MakeFailedError(fmt.Errorf("collection is locked"))
+	}
+
+	secrets := map[dbus.ObjectPath]secretServiceSecret{}
+	for _, path := range items {
+		if item, ok := f.items[path]; ok {
+			secrets[path] = secretServiceSecret{Session: session, Parameters: []byte{}, Value: item.secret, ContentType: "text/plain"}
+		}
+	}
+	return secrets, nil
+}
+
+// Prompt answers the unlock prompt as if the user entered the keyring password
+func (p *fakePrompt) Prompt(string) *dbus.Error {
+	f := p.service
+	f.mu.Lock()
+	f.locked = false
+	f.prompts++
+	unlocked := f.pending
+	f.mu.Unlock()
+
+	if err := f.conn.Emit(fakePromptPath, secretPromptIface+".Completed", false, dbus.MakeVariant(unlocked)); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	return nil
+}
+
+func (c *fakeCollection) CreateItem(properties map[string]dbus.Variant, secret secretServiceSecret, replace bool) (dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	f := c.service
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var attributes map[string]string
+	if err := properties[secretItemIface+".Attributes"].Store(&attributes); err != nil {
+		return "", "", dbus.MakeFailedError(err)
+	}
+
+	if replace {
+		for path, item := range f.items {
+			if maps.Equal(item.attributes, attributes) {
+				item.secret = secret.Value
+				return path, secretNoPrompt, nil
+			}
+		}
+	}
+
+	f.nextID++
+	item := &fakeItem{
+		service:    f,
+		path:       dbus.ObjectPath(fmt.Sprintf("%s/%d", fakeCollectionPath, f.nextID)),
+		attributes: attributes,
+		secret:     secret.Value,
+	}
+	f.items[item.path] = item
+	if err := f.conn.Export(item, item.path, secretItemIface); err != nil {
+		return "", "", dbus.MakeFailedError(err)
+	}
+	if err := f.conn.Export(&fakeItemProperties{item: item}, item.path, "org.freedesktop.DBus.Properties"); err != nil {
+		return "", "", dbus.MakeFailedError(err)
+	}
+	return item.path, secretNoPrompt, nil
+}
+
+func (i *fakeItem) Delete() (dbus.ObjectPath, *dbus.Error) {
+	f := i.service
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.items, i.path)
+	_ = f.conn.Export(nil, i.path, secretItemIface)
+	_ = f.conn.Export(nil, i.path, "org.freedesktop.DBus.Properties")
+	return secretNoPrompt, nil
+}
+
+func (p *fakeItemProperties) Get(iface, property string) (dbus.Variant, *dbus.Error) {
+	f := p.item.service
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if iface != secretItemIface || property != "Attributes" {
+		return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("unknown property %s.%s", iface, property))
+	}
+	return dbus.MakeVariant(maps.Clone(p.item.attributes)), nil
+}
+
+func (fakeSession) Close() *dbus.Error {
+	return nil
+}
+
+func matchAttributes(attributes, query map[string]string) bool {
+	for key, value := range query {
+		if attributes[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSecretServiceStore(t *testing.T) {
+	address := startSessionBus(t)
+	fake := newFakeSecretService(t, address)
+
+	conn, err := dbus.Connect(address)
+	assert.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	store, err := NewSecretServiceStore(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "Desktop keyring (Secret Service)", store.Name())
+
+	// the locked collection is unlocked by the prompt
+	assert.NoError(t, store.SaveProfile(Profile{Name: "ghcr", Registry: "ghcr.io", Username: "alice", Password: "token-1", Namespace: "team-a"}))
+	assert.Equal(t, 1, fake.prompts)
+	assert.NoError(t, store.SaveProfile(Profile{Name: "docker", Registry: "docker.io", Username: "bob", Password: "pass"}))
+
+	// a changed username creates a new item, the old one is removed
+	assert.NoError(t, store.SaveProfile(Profile{Name: "ghcr", Registry: "ghcr.io", Username: "carol", Password: "token-2", Namespace: "team-a"}))
+
+	profiles, err := store.Profiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []Profile{
+		{Name: "docker", Registry: "docker.io", Username: "bob", Password: "pass"},
+		{Name: "ghcr", Registry: "ghcr.io", Username: "carol", Password: "token-2", Namespace: "team-a"},
+	}, profiles)
+
+	assert.NoError(t, store.DeleteProfile("docker"))
+	profiles, err = store.Profiles()
+	assert.NoError(t, err)
+	assert.Len(t, profiles, 1)
+
+	assert.Error(t, store.SaveProfile(Profile{Name: " "}))
+	assert.NoError(t, store.Close())
+}
+
+func TestSecretServiceStore_Unavailable(t *testing.T) {
+	address := startSessionBus(t)
+
+	conn, err := dbus.Connect(address)
+	assert.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = NewSecretServiceStore(conn)
+	assert.ErrorIs(t, err, ErrSecretServiceUnavailable)
+}
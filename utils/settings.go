@@ -1,15 +1,89 @@
 package utils
 
 import (
+	"encoding/json"
+	"log"
+	"maps"
+	"path/filepath"
+	"slices"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/theme"
+	"github.com/javaLux/registrymate/pkg/pullsecret"
 )
 
+// DefaultToastSeconds is how long notifications are shown if nothing else is configured
+const DefaultToastSeconds = 2
+
+// YAMLIndents are the selectable indentations of the secret manifests
+var YAMLIndents = []int{2, 4}
+
 type AppSettings struct {
 	appTheme fyne.ThemeVariant
 	Width    float32
 	Height   float32
 	History  *AppHistory
+	// options of the output formats, e.g. the age recipients for SOPS
+	FormatOptions map[string]string
+	// template of generated secret names, e.g. {{.RegistryHost}}-pull
+	NameTemplate string
+	// backend of the credential profiles, see CredentialBackends
+	CredentialBackend string
+	// namespace filled in on start and used for batch rows without namespace
+	DefaultNamespace string
+	// labels filled in on start and used for batch rows without labels, e.g. owner=team-a
+	DefaultLabels string
+	// name of the output format selected on start, the first format if empty or unknown
+	DefaultFormat string
+	// indentation of the secret manifests, one of YAMLIndents
+	YAMLIndent int
+	// write the docker config as plain JSON to stringData instead of base64 to data
+	StringData bool
+	// how long notifications are shown
+	ToastSeconds int
+	// copy every generated secret to the clipboard
+	CopyOnGenerate bool
+	// clear copied secrets from the clipboard after this many seconds, 0 keeps them
+	ClipboardClearSeconds int
+	// read-only settings file whose settings apply where the user did not change them
+	TeamDefaultsPath string
+	// policy file every generated secret is checked against, see pullsecret.Policy
+	PolicyPath string
+
+	team      *SettingsFile
+	teamErr   error
+	policy    *pullsecret.Policy
+	policyErr error
+}
+
+// ToastDuration returns how long notifications are shown
+func (a *AppSettings) ToastDuration() time.Duration {
+	return time.Duration(a.ToastSeconds) * time.Second
+}
+
+// EncodeOptions returns how the secret manifests are written
+func (a *AppSettings) EncodeOptions() pullsecret.EncodeOptions {
+	return pullsecret.EncodeOptions{Indent: a.YAMLIndent, StringData: a.StringData}
+}
+
+// normalize replaces values out of range, e.g. edited by hand in the preferences.json
+func (a *AppSettings) normalize() {
+	if !slices.Contains(YAMLIndents, a.YAMLIndent) {
+		a.YAMLIndent = pullsecret.DefaultIndent
+	}
+	if a.ToastSeconds < 1 {
+		a.ToastSeconds = DefaultToastSeconds
+	}
+	if a.ClipboardClearSeconds < 0 {
+		a.ClipboardClearSeconds = 0
+	}
+	if a.DefaultNamespace != "" && !IsK8sNamespaceValid(a.DefaultNamespace) {
+		a.DefaultNamespace = ""
+	}
+	if _, err := pullsecret.ParseLabels(a.DefaultLabels); err != nil {
+		a.DefaultLabels = ""
+	}
 }
 
 func (a *AppSettings) SetThemeVariant(variant fyne.ThemeVariant) {
@@ -27,12 +101,12 @@ func (a *AppSettings) IsLightTheme() bool {
 // Load saved App settings or use default
 // App settings stored as JSON in the user config directory:  ~/<Config-Dir>/fyne/<appname>/preferences.json
 func LoadAppSettings(a fyne.App) *AppSettings {
+	migratePreferences(a.Preferences())
+
 	isLightTheme := a.Preferences().BoolWithFallback(PrefKeyIsLightTheme, false)
 	width := a.Preferences().FloatWithFallback(PrefKeyWindowWidth, 650)
 	height := a.Preferences().FloatWithFallback(PrefKeyWindowHeight, 500)
-	registries := a.Preferences().StringListWithFallback(PrefKeyRegistries, []string{})
-	namespaces := a.Preferences().StringListWithFallback(PrefKeyNamespaces, []string{})
-	names := a.Preferences().StringListWithFallback(PrefKeyNames, []string{})
+	credentialBackend := a.Preferences().StringWithFallback(PrefKeyCredBackend, CredentialBackendAuto)
 
 	var appTheme fyne.ThemeVariant
 	if isLightTheme {
@@ -41,17 +115,24 @@ func LoadAppSettings(a fyne.App) *AppSettings {
 		appTheme = theme.VariantDark
 	}
 
-	history := NewAppHistory()
-	history.SetRegistries(registries)
-	history.SetNamespaces(namespaces)
-	history.SetNames(names)
-
-	return &AppSettings{
+	settings := &AppSettings{
 		appTheme: appTheme,
 		Width:    float32(width),
 		Height:   float32(height),
-		History:  history,
+		History:  loadHistory(a.Preferences()),
+
+		CredentialBackend: credentialBackend,
+		TeamDefaultsPath:  a.Preferences().String(PrefKeyTeamDefaults),
+	}
+	if err := settings.loadTeamDefaults(); err != nil {
+		log.Printf("App-Settings - failed to load the team defaults: %v", err)
+	}
+	settings.loadValues(a.Preferences())
+	if err := settings.loadPolicy(); err != nil {
+		log.Printf("App-Settings - failed to load the policy: %v", err)
 	}
+
+	return settings
 }
 
 // Save App settings before exit
@@ -59,7 +140,238 @@ func (a *AppSettings) SaveAppSettings(app fyne.App) {
 	app.Preferences().SetBool(PrefKeyIsLightTheme, a.IsLightTheme())
 	app.Preferences().SetFloat(PrefKeyWindowWidth, float64(a.Width))
 	app.Preferences().SetFloat(PrefKeyWindowHeight, float64(a.Height))
-	app.Preferences().SetStringList(PrefKeyRegistries, a.History.Registries)
-	app.Preferences().SetStringList(PrefKeyNamespaces, a.History.Namespaces)
-	app.Preferences().SetStringList(PrefKeyNames, a.History.Names)
+	saveHistory(app.Preferences(), a.History)
+	app.Preferences().SetString(PrefKeyCredBackend, a.CredentialBackend)
+	app.Preferences().SetString(PrefKeyTeamDefaults, a.TeamDefaultsPath)
+	a.saveValues(app.Preferences())
+	app.Preferences().SetInt(PrefKeySettingsVersion, SettingsVersion)
+}
+
+// defaults returns the built-in settings with the team defaults applied
+func (a *AppSettings) defaults() *AppSettings {
+	defaults := &AppSettings{
+		History:       NewAppHistory(),
+		FormatOptions: map[string]string{},
+		YAMLIndent:    pullsecret.DefaultIndent,
+		ToastSeconds:  DefaultToastSeconds,
+	}
+	if a.team != nil {
+		defaults.Apply(a.team.Settings)
+		// the policy is usually checked in next to the team defaults
+		if defaults.PolicyPath != "" && !filepath.IsAbs(defaults.PolicyPath) {
+			defaults.PolicyPath = filepath.Join(filepath.Dir(a.TeamDefaultsPath), defaults.PolicyPath)
+		}
+	}
+	return defaults
+}
+
+// loadValues reads the settings of the settings window, settings the user never changed fall back to the defaults
+func (a *AppSettings) loadValues(p fyne.Preferences) {
+	d := a.defaults()
+
+	a.DefaultNamespace = p.StringWithFallback(PrefKeyDefaultNamespace, d.DefaultNamespace)
+	a.DefaultLabels = p.StringWithFallback(PrefKeyDefaultLabels, d.DefaultLabels)
+	a.DefaultFormat = p.StringWithFallback(PrefKeyDefaultFormat, d.DefaultFormat)
+	a.YAMLIndent = p.IntWithFallback(PrefKeyYAMLIndent, d.YAMLIndent)
+	a.StringData = p.BoolWithFallback(PrefKeyStringData, d.StringData)
+	a.NameTemplate = p.StringWithFallback(PrefKeyNameTemplate, d.NameTemplate)
+	a.ToastSeconds = p.IntWithFallback(PrefKeyToastSeconds, d.ToastSeconds)
+	a.CopyOnGenerate = p.BoolWithFallback(PrefKeyCopyOnGenerate, d.CopyOnGenerate)
+	a.ClipboardClearSeconds = p.IntWithFallback(PrefKeyClipboardClear, d.ClipboardClearSeconds)
+	a.PolicyPath = p.StringWithFallback(PrefKeyPolicy, d.PolicyPath)
+	a.History.SetOrder(HistoryOrder(p.StringWithFallback(PrefKeyHistoryOrder, string(d.History.Order()))))
+	a.History.SetMaxEntries(p.IntWithFallback(PrefKeyMaxHistory, d.History.MaxEntries()))
+
+	a.FormatOptions = d.FormatOptions
+	own := map[string]string{}
+	if err := json.Unmarshal([]byte(p.StringWithFallback(PrefKeyFormatOpts, "{}")), &own); err != nil {
+		log.Printf("App-Settings - failed to load output format options: %v", err)
+	}
+	maps.Copy(a.FormatOptions, own)
+
+	a.normalize()
+}
+
+// saveValues stores the settings which differ from the defaults, the others follow later changes of the team defaults
+func (a *AppSettings) saveValues(p fyne.Preferences) {
+	d := a.defaults()
+
+	setPref(p, PrefKeyDefaultNamespace, a.DefaultNamespace, d.DefaultNamespace, p.SetString)
+	setPref(p, PrefKeyDefaultLabels, a.DefaultLabels, d.DefaultLabels, p.SetString)
+	setPref(p, PrefKeyDefaultFormat, a.DefaultFormat, d.DefaultFormat, p.SetString)
+	setPref(p, PrefKeyYAMLIndent, a.YAMLIndent, d.YAMLIndent, p.SetInt)
+	setPref(p, PrefKeyStringData, a.StringData, d.StringData, p.SetBool)
+	setPref(p, PrefKeyNameTemplate, a.NameTemplate, d.NameTemplate, p.SetString)
+	setPref(p, PrefKeyToastSeconds, a.ToastSeconds, d.ToastSeconds, p.SetInt)
+	setPref(p, PrefKeyCopyOnGenerate, a.CopyOnGenerate, d.CopyOnGenerate, p.SetBool)
+	setPref(p, PrefKeyClipboardClear, a.ClipboardClearSeconds, d.ClipboardClearSeconds, p.SetInt)
+	setPref(p, PrefKeyPolicy, a.PolicyPath, d.PolicyPath, p.SetString)
+	setPref(p, PrefKeyHistoryOrder, string(a.History.Order()), string(d.History.Order()), p.SetString)
+	setPref(p, PrefKeyMaxHistory, a.History.MaxEntries(), d.History.MaxEntries(), p.SetInt)
+
+	own := maps.Clone(a.FormatOptions)
+	maps.DeleteFunc(own, func(key, value string) bool {
+		inherited, ok := d.FormatOptions[key]
+		return ok && inherited == value
+	})
+	if formatOptions, err := json.Marshal(own); err != nil {
+		log.Printf("App-Settings - failed to save output format options: %v", err)
+	} else {
+		p.SetString(PrefKeyFormatOpts, string(formatOptions))
+	}
+}
+
+// setPref stores the value, or removes it if it equals the default
+func setPref[T comparable](p fyne.Preferences, key string, value, fallback T, set func(string, T)) {
+	if value == fallback {
+		p.RemoveValue(key)
+		return
+	}
+	set(key, value)
+}
+
+// TeamDefaults returns the loaded team defaults, nil if none are configured or they failed to load
+func (a *AppSettings) TeamDefaults() *SettingsFile {
+	return a.team
+}
+
+// TeamDefaultsError returns why the configured team defaults could not be loaded
+func (a *AppSettings) TeamDefaultsError() error {
+	return a.teamErr
+}
+
+// loadTeamDefaults reads the team defaults file, the settings fall back to the built-in defaults on errors
+func (a *AppSettings) loadTeamDefaults() error {
+	a.team, a.teamErr = nil, nil
+	if a.History != nil {
+		a.History.SetTeam(nil)
+	}
+	if a.TeamDefaultsPath == "" {
+		return nil
+	}
+
+	a.team, a.teamErr = ReadSettingsFile(a.TeamDefaultsPath)
+	if a.teamErr != nil {
+		a.team = nil
+	} else if a.History != nil {
+		a.History.SetTeam(a.team.History)
+	}
+	return a.teamErr
+}
+
+// SetTeamDefaults switches to another team defaults file, or none for an empty path. The own settings are
+// kept, settings which equal the old defaults follow the new ones.
+func (a *AppSettings) SetTeamDefaults(p fyne.Preferences, path string) error {
+	a.saveValues(p)
+	a.TeamDefaultsPath = path
+	p.SetString(PrefKeyTeamDefaults, path)
+	err := a.loadTeamDefaults()
+	a.loadValues(p)
+	// the team defaults may set another policy
+	if policyErr := a.loadPolicy(); err == nil {
+		err = policyErr
+	}
+	return err
+}
+
+// ReloadTeamDefaults reads the team defaults file again, e.g. after a pull of its Git repository
+func (a *AppSettings) ReloadTeamDefaults(p fyne.Preferences) error {
+	return a.SetTeamDefaults(p, a.TeamDefaultsPath)
+}
+
+// Policy returns the loaded policy, nil if none is configured or it failed to load
+func (a *AppSettings) Policy() *pullsecret.Policy {
+	return a.policy
+}
+
+// PolicyError returns why the configured policy could not be loaded
+func (a *AppSettings) PolicyError() error {
+	return a.policyErr
+}
+
+// loadPolicy reads the policy file, secrets are not checked if it fails to load
+func (a *AppSettings) loadPolicy() error {
+	a.policy, a.policyErr = nil, nil
+	if a.PolicyPath == "" {
+		return nil
+	}
+
+	a.policy, a.policyErr = pullsecret.LoadPolicy(a.PolicyPath)
+	if a.policyErr != nil {
+		a.policy = nil
+	}
+	return a.policyErr
+}
+
+// SetPolicy switches to another policy file, or none for an empty path
+func (a *AppSettings) SetPolicy(p fyne.Preferences, path string) error {
+	a.PolicyPath = path
+	a.saveValues(p)
+	return a.loadPolicy()
+}
+
+// ReloadPolicy reads the policy file again
+func (a *AppSettings) ReloadPolicy() error {
+	return a.loadPolicy()
+}
+
+// migratePreferences moves the keys of older settings versions to the current ones
+func migratePreferences(p fyne.Preferences) {
+	version := p.Int(PrefKeySettingsVersion)
+	if version >= SettingsVersion {
+		return
+	}
+
+	if version < 1 {
+		// unversioned keys of the first releases
+		if nameTemplate := p.String(PrefKeyNameTemplateV0); nameTemplate != "" && p.String(PrefKeyNameTemplate) == "" {
+			p.SetString(PrefKeyNameTemplate, nameTemplate)
+		}
+		p.RemoveValue(PrefKeyNameTemplateV0)
+	}
+
+	p.SetInt(PrefKeySettingsVersion, SettingsVersion)
+}
+
+// loadHistory reads the history entries, the bare value lists of older versions are migrated
+func loadHistory(p fyne.Preferences) *AppHistory {
+	history := NewAppHistory()
+
+	if stored := p.String(PrefKeyHistory); stored != "" {
+		if err := json.Unmarshal([]byte(stored), history); err != nil {
+			log.Printf("App-Settings - failed to load history: %v", err)
+			history.Clear()
+		}
+	} else {
+		history.SetRegistries(NewLegacyHistoryEntries(p.StringListWithFallback(PrefKeyRegistries, []string{})))
+		history.SetNamespaces(NewLegacyHistoryEntries(p.StringListWithFallback(PrefKeyNamespaces, []string{})))
+		history.SetNames(NewLegacyHistoryEntries(p.StringListWithFallback(PrefKeyNames, []string{})))
+
+		pinned := map[HistoryKind][]string{}
+		if err := json.Unmarshal([]byte(p.StringWithFallback(PrefKeyPinned, "{}")), &pinned); err != nil {
+			log.Printf("App-Settings - failed to load pinned history entries: %v", err)
+		}
+		for kind, values := range pinned {
+			for _, value := range values {
+				history.Pin(kind, value, true)
+			}
+		}
+	}
+
+	return history
+}
+
+// saveHistory stores the history entries and removes the migrated keys of older versions
+func saveHistory(p fyne.Preferences, history *AppHistory) {
+	stored, err := json.Marshal(history)
+	if err != nil {
+		log.Printf("App-Settings - failed to save history: %v", err)
+		return
+	}
+
+	p.SetString(PrefKeyHistory, string(stored))
+
+	for _, key := range []string{PrefKeyRegistries, PrefKeyNamespaces, PrefKeyNames, PrefKeyPinned} {
+		p.RemoveValue(key)
+	}
 }
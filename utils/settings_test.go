@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAppSettings_Defaults(t *testing.T) {
+	app := test.NewTempApp(t)
+
+	settings := LoadAppSettings(app)
+	assert.Equal(t, 2, settings.YAMLIndent)
+	assert.Equal(t, 2*time.Second, settings.ToastDuration())
+	assert.False(t, settings.StringData)
+	assert.Zero(t, settings.ClipboardClearSeconds)
+	assert.Equal(t, SettingsVersion, app.Preferences().Int(PrefKeySettingsVersion))
+}
+
+func TestLoadAppSettings_Migration(t *testing.T) {
+	app := test.NewTempApp(t)
+	app.Preferences().SetString(PrefKeyNameTemplateV0, "{{.RegistryHost}}-pull")
+
+	settings := LoadAppSettings(app)
+	assert.Equal(t, "{{.RegistryHost}}-pull", settings.NameTemplate)
+	assert.Empty(t, app.Preferences().String(PrefKeyNameTemplateV0))
+	assert.Equal(t, "{{.RegistryHost}}-pull", app.Preferences().String(PrefKeyNameTemplate))
+}
+
+func TestAppSettings_SaveLoad(t *testing.T) {
+	app := test.NewTempApp(t)
+
+	settings := LoadAppSettings(app)
+	settings.DefaultNamespace = "team-a"
+	settings.DefaultFormat = "Terraform"
+	settings.YAMLIndent = 4
+	settings.StringData = true
+	settings.ToastSeconds = 5
+	settings.CopyOnGenerate = true
+	settings.ClipboardClearSeconds = 30
+	settings.History.SetOrder(HistoryOrderMostUsed)
+	settings.History.SetMaxEntries(50)
+	settings.SaveAppSettings(app)
+
+	reloaded := LoadAppSettings(app)
+	assert.Equal(t, settings.EncodeOptions(), reloaded.EncodeOptions())
+	assert.Equal(t, "team-a", reloaded.DefaultNamespace)
+	assert.Equal(t, "Terraform", reloaded.DefaultFormat)
+	assert.Equal(t, 5*time.Second, reloaded.ToastDuration())
+	assert.True(t, reloaded.CopyOnGenerate)
+	assert.Equal(t, 30, reloaded.ClipboardClearSeconds)
+	assert.Equal(t, HistoryOrderMostUsed, reloaded.History.Order())
+	assert.Equal(t, 50, reloaded.History.MaxEntries())
+
+	// values edited by hand are replaced
+	app.Preferences().SetInt(PrefKeyYAMLIndent, 3)
+	app.Preferences().SetInt(PrefKeyToastSeconds, -1)
+	app.Preferences().SetString(PrefKeyDefaultNamespace, "Invalid_Namespace")
+	reloaded = LoadAppSettings(app)
+	assert.Equal(t, 2, reloaded.YAMLIndent)
+	assert.Equal(t, DefaultToastSeconds, reloaded.ToastSeconds)
+	assert.Empty(t, reloaded.DefaultNamespace)
+}
+
+func TestAppSettings_TeamDefaults(t *testing.T) {
+	app := test.NewTempApp(t)
+	path := filepath.Join(t.TempDir(), "team.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`version: 1
+settings:
+  defaultNamespace: team-a
+  yamlIndent: 4
+  formatOptions:
+    sops.recipients: age1team
+`), 0o600))
+
+	settings := LoadAppSettings(app)
+	assert.NoError(t, settings.SetTeamDefaults(app.Preferences(), path))
+	assert.Equal(t, "team-a", settings.DefaultNamespace)
+	assert.Equal(t, 4, settings.YAMLIndent)
+	assert.Equal(t, "age1team", settings.FormatOptions["sops.recipients"])
+
+	// own settings win over the team defaults, unchanged ones follow them
+	settings.DefaultNamespace = "own"
+	settings.SaveAppSettings(app)
+	assert.NoError(t, os.WriteFile(path, []byte("version: 1\nsettings:\n  defaultNamespace: team-b\n  yamlIndent: 2\n"), 0o600))
+
+	reloaded := LoadAppSettings(app)
+	assert.NoError(t, reloaded.TeamDefaultsError())
+	assert.Equal(t, "own", reloaded.DefaultNamespace)
+	assert.Equal(t, 2, reloaded.YAMLIndent)
+	assert.Empty(t, reloaded.FormatOptions["sops.recipients"])
+
+	// a broken file falls back to the built-in defaults
+	assert.NoError(t, os.WriteFile(path, []byte("version: 1\nsettings:\n  unknown: true\n"), 0o600))
+	assert.Error(t, reloaded.ReloadTeamDefaults(app.Preferences()))
+	assert.Error(t, reloaded.TeamDefaultsError())
+	assert.Nil(t, reloaded.TeamDefaults())
+	assert.Equal(t, "own", reloaded.DefaultNamespace)
+
+	assert.NoError(t, reloaded.SetTeamDefaults(app.Preferences(), ""))
+	assert.NoError(t, reloaded.TeamDefaultsError())
+}
+
+func TestAppSettings_TeamHistory(t *testing.T) {
+	app := test.NewTempApp(t)
+	path := filepath.Join(t.TempDir(), "team.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`version: 1
+history:
+  names: []
+  namespaces:
+    - value: team-a
+      count: 1
+  registries:
+    - value: ghcr.io
+      count: 1
+    - value: quay.io
+      count: 1
+  links:
+    - registry: ghcr.io
+      username: team-bot
+      count: 1
+`), 0o600))
+
+	settings := LoadAppSettings(app)
+	settings.History.AddRegistry("quay.io")
+	settings.History.AddLink("ghcr.io", "me", "", "")
+	assert.NoError(t, settings.SetTeamDefaults(app.Preferences(), path))
+
+	// the own entries come first, the team history only adds values
+	assert.Equal(t, []string{"quay.io", "ghcr.io"}, settings.History.SortedRegistries())
+	assert.Equal(t, []string{"team-a"}, settings.History.SortedNamespaces())
+	assert.Equal(t, []string{"me", "team-bot"}, settings.History.RegistryUsernames("ghcr.io"))
+
+	// it is read-only and never saved
+	assert.Equal(t, []string{"quay.io"}, settings.History.Search(HistoryRegistries, ""))
+	settings.SaveAppSettings(app)
+	assert.Equal(t, []string{"quay.io"}, loadHistory(app.Preferences()).SortedRegistries())
+
+	assert.NoError(t, settings.SetTeamDefaults(app.Preferences(), ""))
+	assert.Equal(t, []string{"quay.io"}, settings.History.SortedRegistries())
+}
+
+func TestAppSettings_Policy(t *testing.T) {
+	app := test.NewTempApp(t)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte("version: 1\nrequiredLabels: [owner]\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "team.yaml"), []byte("version: 1\nsettings:\n  policyFile: policy.yaml\n  defaultLabels: owner=team-a\n"), 0o600))
+
+	settings := LoadAppSettings(app)
+	assert.Nil(t, settings.Policy())
+
+	// a relative policy of the team defaults is next to the team defaults file
+	assert.NoError(t, settings.SetTeamDefaults(app.Preferences(), filepath.Join(dir, "team.yaml")))
+	assert.Equal(t, filepath.Join(dir, "policy.yaml"), settings.PolicyPath)
+	assert.Equal(t, []string{"owner"}, settings.Policy().RequiredLabels)
+	assert.Equal(t, "owner=team-a", settings.DefaultLabels)
+
+	reloaded := LoadAppSettings(app)
+	assert.NoError(t, reloaded.PolicyError())
+	assert.NotNil(t, reloaded.Policy())
+
+	assert.Error(t, reloaded.SetPolicy(app.Preferences(), filepath.Join(dir, "missing.yaml")))
+	assert.Error(t, reloaded.PolicyError())
+	assert.Nil(t, reloaded.Policy())
+	assert.Equal(t, filepath.Join(dir, "missing.yaml"), LoadAppSettings(app).PolicyPath)
+
+	assert.NoError(t, reloaded.SetPolicy(app.Preferences(), ""))
+	assert.Nil(t, reloaded.Policy())
+}
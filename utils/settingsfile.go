@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SettingsFileVersion is the version of the settings files written by MarshalSettingsFile
+const SettingsFileVersion = 1
+
+// SettingsFile shares settings, history and profile templates as YAML, e.g. as team defaults
+// checked into a Git repository. Passwords are never part of it.
+type SettingsFile struct {
+	Version  int               `yaml:"version"`
+	Settings *SettingsValues   `yaml:"settings,omitempty"`
+	History  *AppHistory       `yaml:"history,omitempty"`
+	Profiles []ProfileTemplate `yaml:"profiles,omitempty"`
+}
+
+// SettingsValues are the shareable settings, values which are not set are left unchanged.
+// Window size, theme and the profile storage belong to the machine and are not shared.
+// A relative policy file of team defaults is relative to the team defaults file.
+type SettingsValues struct {
+	DefaultNamespace      *string           `yaml:"defaultNamespace,omitempty"`
+	DefaultLabels         *string           `yaml:"defaultLabels,omitempty"`
+	DefaultFormat         *string           `yaml:"defaultFormat,omitempty"`
+	YAMLIndent            *int              `yaml:"yamlIndent,omitempty"`
+	StringData            *bool             `yaml:"stringData,omitempty"`
+	NameTemplate          *string           `yaml:"nameTemplate,omitempty"`
+	ToastSeconds          *int              `yaml:"toastSeconds,omitempty"`
+	CopyOnGenerate        *bool             `yaml:"copyOnGenerate,omitempty"`
+	ClipboardClearSeconds *int              `yaml:"clipboardClearSeconds,omitempty"`
+	HistoryOrder          *HistoryOrder     `yaml:"historyOrder,omitempty"`
+	MaxHistory            *int              `yaml:"maxHistory,omitempty"`
+	PolicyPath            *string           `yaml:"policyFile,omitempty"`
+	FormatOptions         map[string]string `yaml:"formatOptions,omitempty"`
+}
+
+// ProfileTemplate is a credential profile without password
+type ProfileTemplate struct {
+	Name      string `yaml:"name"`
+	Registry  string `yaml:"registry"`
+	Username  string `yaml:"username,omitempty"`
+	Secret    string `yaml:"secretName,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// NewProfileTemplate returns the profile without its password
+func NewProfileTemplate(p Profile) ProfileTemplate {
+	return ProfileTemplate{
+		Name:      p.Name,
+		Registry:  p.Registry,
+		Username:  p.Username,
+		Secret:    p.Secret,
+		Namespace: p.Namespace,
+	}
+}
+
+// Profile returns the template as profile with an empty password
+func (t ProfileTemplate) Profile() Profile {
+	return Profile{
+		Name:      t.Name,
+		Registry:  t.Registry,
+		Username:  t.Username,
+		Secret:    t.Secret,
+		Namespace: t.Namespace,
+	}
+}
+
+// Values returns all shareable settings
+func (a *AppSettings) Values() *SettingsValues {
+	return &SettingsValues{
+		DefaultNamespace:      ptr(a.DefaultNamespace),
+		DefaultLabels:         ptr(a.DefaultLabels),
+		DefaultFormat:         ptr(a.DefaultFormat),
+		YAMLIndent:            ptr(a.YAMLIndent),
+		StringData:            ptr(a.StringData),
+		NameTemplate:          ptr(a.NameTemplate),
+		ToastSeconds:          ptr(a.ToastSeconds),
+		CopyOnGenerate:        ptr(a.CopyOnGenerate),
+		ClipboardClearSeconds: ptr(a.ClipboardClearSeconds),
+		HistoryOrder:          ptr(a.History.Order()),
+		MaxHistory:            ptr(a.History.MaxEntries()),
+		PolicyPath:            ptr(a.PolicyPath),
+		FormatOptions:         maps.Clone(a.FormatOptions),
+	}
+}
+
+// Apply sets the values which are set, format options are merged and values out of range are replaced
+func (a *AppSettings) Apply(v *SettingsValues) {
+	if v == nil {
+		return
+	}
+
+	setIfNotNil(&a.DefaultNamespace, v.DefaultNamespace)
+	setIfNotNil(&a.DefaultLabels, v.DefaultLabels)
+	setIfNotNil(&a.DefaultFormat, v.DefaultFormat)
+	setIfNotNil(&a.YAMLIndent, v.YAMLIndent)
+	setIfNotNil(&a.StringData, v.StringData)
+	setIfNotNil(&a.NameTemplate, v.NameTemplate)
+	setIfNotNil(&a.ToastSeconds, v.ToastSeconds)
+	setIfNotNil(&a.CopyOnGenerate, v.CopyOnGenerate)
+	setIfNotNil(&a.ClipboardClearSeconds, v.ClipboardClearSeconds)
+	setIfNotNil(&a.PolicyPath, v.PolicyPath)
+	if v.HistoryOrder != nil {
+		a.History.SetOrder(*v.HistoryOrder)
+	}
+	if v.MaxHistory != nil {
+		a.History.SetMaxEntries(*v.MaxHistory)
+	}
+	if len(v.FormatOptions) > 0 {
+		if a.FormatOptions == nil {
+			a.FormatOptions = map[string]string{}
+		}
+		maps.Copy(a.FormatOptions, v.FormatOptions)
+	}
+
+	a.normalize()
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func setIfNotNil[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// MarshalSettingsFile returns the settings file as YAML in the current version
+func MarshalSettingsFile(f SettingsFile) ([]byte, error) {
+	f.Version = SettingsFileVersion
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseSettingsFile reads a settings file, unknown keys are rejected to catch typos in hand-written files
+func ParseSettingsFile(content []byte) (*SettingsFile, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+
+	var f SettingsFile
+	if err := dec.Decode(&f); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the settings file is empty")
+		}
+		return nil, fmt.Errorf("invalid settings file: %w", err)
+	}
+
+	switch {
+	case f.Version == 0:
+		return nil, errors.New("invalid settings file: version is missing")
+	case f.Version > SettingsFileVersion:
+		return nil, fmt.Errorf("the settings file has version %d, this version of the app reads up to version %d", f.Version, SettingsFileVersion)
+	}
+
+	names := map[string]bool{}
+	for i, p := range f.Profiles {
+		p.Name = strings.TrimSpace(p.Name)
+		p.Registry = strings.TrimSpace(p.Registry)
+		if p.Name == "" || p.Registry == "" {
+			return nil, fmt.Errorf("profile %d: name and registry are required", i+1)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("profile %q is defined twice", p.Name)
+		}
+		names[p.Name] = true
+		f.Profiles[i] = p
+	}
+
+	return &f, nil
+}
+
+// ReadSettingsFile reads and parses a settings file
+func ReadSettingsFile(path string) (*SettingsFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ParseSettingsFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
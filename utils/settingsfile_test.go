@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsFile_ExportImport(t *testing.T) {
+	settings := LoadAppSettings(test.NewTempApp(t))
+	settings.NameTemplate = "{{.RegistryHost}}-pull"
+	settings.FormatOptions = map[string]string{"terraform.passwordVariable": "registry_password"}
+	settings.History.AddRegistry("ghcr.io")
+	settings.History.AddLink("ghcr.io", "alice", "ghcr-pull", "team-a")
+
+	exported, err := MarshalSettingsFile(SettingsFile{
+		Settings: settings.Values(),
+		History:  settings.History,
+		Profiles: []ProfileTemplate{NewProfileTemplate(Profile{Name: "ghcr", Registry: "ghcr.io", Username: "alice", Password: "secret"})},
+	})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(exported), "secret\n")
+	assert.Contains(t, string(exported), "version: 1\n")
+
+	parsed, err := ParseSettingsFile(exported)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", parsed.Profiles[0].Profile().Username)
+	assert.Empty(t, parsed.Profiles[0].Profile().Password)
+
+	imported := LoadAppSettings(test.NewTempApp(t))
+	imported.Apply(parsed.Settings)
+	assert.Equal(t, settings.Values(), imported.Values())
+	assert.Equal(t, 1, imported.History.Merge(parsed.History))
+	assert.Equal(t, []string{"alice"}, imported.History.RegistryUsernames("ghcr.io"))
+
+	// unset values are left unchanged
+	imported.Apply(&SettingsValues{ToastSeconds: ptr(5)})
+	assert.Equal(t, "{{.RegistryHost}}-pull", imported.NameTemplate)
+	assert.Equal(t, 5, imported.ToastSeconds)
+}
+
+func TestParseSettingsFile_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":           "",
+		"no version":      "settings:\n  yamlIndent: 4\n",
+		"newer version":   "version: 99\n",
+		"unknown key":     "version: 1\nsetings: {}\n",
+		"profile":         "version: 1\nprofiles:\n  - name: ghcr\n",
+		"duplicate":       "version: 1\nprofiles:\n  - {name: a, registry: ghcr.io}\n  - {name: a, registry: quay.io}\n",
+		"no settings map": "version: 1\nsettings: [1]\n",
+	} {
+		_, err := ParseSettingsFile([]byte(content))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// VaultFileName is the file of the profile vault, stored next to preferences.json
+	VaultFileName = "profiles.vault"
+
+	vaultVersion  = 1
+	vaultKDF      = "argon2id"
+	vaultCipher   = "xchacha20-poly1305"
+	vaultSaltSize = 16
+	vaultFileMode = 0o600
+)
+
+// ErrWrongPassphrase is returned if the vault cannot be decrypted with the passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or damaged vault")
+
+// Profile is a saved set of registry credentials and default metadata
+type Profile struct {
+	Name      string `json:"name"`
+	Registry  string `json:"registry"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Secret    string `json:"secretName,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// KDFParams are the argon2id parameters of the vault key, stored with the vault
+type KDFParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// DefaultKDFParams follow the second recommended option of RFC 9106 (64 MiB, 3 passes)
+var DefaultKDFParams = KDFParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// valid reports whether argon2 accepts the parameters, it panics on zero threads
+func (p KDFParams) valid() bool {
+	return p.Time > 0 && p.Threads > 0 && p.Memory >= 8*uint32(p.Threads)
+}
+
+// vaultFile is the file format, everything except the KDF parameters and the salt is encrypted
+type vaultFile struct {
+	Version    int       `json:"version"`
+	KDF        string    `json:"kdf"`
+	Params     KDFParams `json:"params"`
+	Cipher     string    `json:"cipher"`
+	Salt       []byte    `json:"salt"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// Vault holds the profiles decrypted in memory, changes are written by Save
+type Vault struct {
+	path     string
+	key      []byte
+	salt     []byte
+	params   KDFParams
+	profiles map[string]Profile
+}
+
+// VaultPath returns the path of the vault in the directory of the app preferences
+func VaultPath(configDir string) string {
+	return filepath.Join(configDir, VaultFileName)
+}
+
+// VaultExists reports whether a vault was already created at path
+func VaultExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// CreateVault returns a new empty vault for the passphrase, it is written on the first Save
+func CreateVault(path, passphrase string, params KDFParams) (*Vault, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	if !params.valid() {
+		return nil, fmt.Errorf("invalid KDF parameters %+v", params)
+	}
+
+	salt := make([]byte, vaultSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return &Vault{
+		path:     path,
+		key:      deriveVaultKey(passphrase, salt, params),
+		salt:     salt,
+		params:   params,
+		profiles: map[string]Profile{},
+	}, nil
+}
+
+// OpenVault decrypts the vault at path, ErrWrongPassphrase is returned for a wrong passphrase
+func OpenVault(path, passphrase string) (*Vault, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to read vault %s: %w", path, err)
+	}
+	if file.Version != vaultVersion || file.KDF != vaultKDF || file.Cipher != vaultCipher || !file.Params.valid() {
+		return nil, fmt.Errorf("unsupported vault %s: version %d, %s, %s", path, file.Version, file.KDF, file.Cipher)
+	}
+
+	key := deriveVaultKey(passphrase, file.Salt, file.Params)
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, vaultAdditionalData(file))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	var profiles []Profile
+	if err := json.Unmarshal(plaintext, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to read vault profiles: %w", err)
+	}
+
+	v := &Vault{
+		path:     path,
+		key:      key,
+		salt:     file.Salt,
+		params:   file.Params,
+		profiles: make(map[string]Profile, len(profiles)),
+	}
+	for _, p := range profiles {
+		v.profiles[p.Name] = p
+	}
+	return v, nil
+}
+
+// Save encrypts all profiles with a fresh nonce and replaces the vault file
+func (v *Vault) Save() error {
+	plaintext, err := json.Marshal(v.Profiles())
+	if err != nil {
+		return err
+	}
+
+	aead, err := chacha20poly1305.NewX(v.key)
+	if err != nil {
+		return err
+	}
+
+	file := vaultFile{
+		Version: vaultVersion,
+		KDF:     vaultKDF,
+		Params:  v.params,
+		Cipher:  vaultCipher,
+		Salt:    v.salt,
+		Nonce:   make([]byte, aead.NonceSize()),
+	}
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, vaultAdditionalData(file))
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, a failed write must not destroy the vault
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, content, vaultFileMode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, v.path); err != nil {
+		if removeErr := os.Remove(tmp); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			return errors.Join(err, removeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Path returns the file of the vault
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Profiles returns all profiles sorted by name
+func (v *Vault) Profiles() []Profile {
+	profiles := make([]Profile, 0, len(v.profiles))
+	for _, p := range v.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// Profile returns the profile with the given name
+func (v *Vault) Profile(name string) (Profile, bool) {
+	p, ok := v.profiles[name]
+	return p, ok
+}
+
+// SetProfile adds or replaces the profile with the same name
+func (v *Vault) SetProfile(p Profile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("profile name must not be empty")
+	}
+	v.profiles[p.Name] = p
+	return nil
+}
+
+// RemoveProfile deletes the profile with the given name
+func (v *Vault) RemoveProfile(name string) {
+	delete(v.profiles, name)
+}
+
+// ProfileNames returns the names of all profiles sorted
+func (v *Vault) ProfileNames() []string {
+	names := make([]string, 0, len(v.profiles))
+	for name := range v.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func deriveVaultKey(passphrase string, salt []byte, params KDFParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, chacha20poly1305.KeySize)
+}
+
+// vaultAdditionalData binds the unencrypted header to the ciphertext, a changed header fails to decrypt
+func vaultAdditionalData(file vaultFile) []byte {
+	return fmt.Appendf(nil, "%d|%s|%d|%d|%d|%s|%x", file.Version, file.KDF,
+		file.Params.Time, file.Params.Memory, file.Params.Threads, file.Cipher, file.Salt)
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testKDFParams keep the tests fast, the app uses DefaultKDFParams
+var testKDFParams = KDFParams{Time: 1, Memory: 1024, Threads: 1}
+
+func TestVault_SaveAndOpen(t *testing.T) {
+	path := VaultPath(t.TempDir())
+	assert.False(t, VaultExists(path))
+
+	vault, err := CreateVault(path, "correct horse", testKDFParams)
+	assert.NoError(t, err)
+	assert.NoError(t, vault.SetProfile(Profile{Name: " ghcr ", Registry: "ghcr.io", Username: "alice", Password: "s3cr3t-token", Namespace: "team-a"}))
+	assert.NoError(t, vault.SetProfile(Profile{Name: "docker", Registry: "docker.io", Username: "bob", Password: "pass"}))
+	assert.Error(t, vault.SetProfile(Profile{Name: " "}))
+	assert.NoError(t, vault.Save())
+	assert.True(t, VaultExists(path))
+
+	// the passwords must not be readable from the file
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "s3cr3t-token")
+	assert.NotContains(t, string(content), "alice")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	if filepath.Separator == '/' {
+		assert.Equal(t, os.FileMode(vaultFileMode), info.Mode().Perm())
+	}
+
+	opened, err := OpenVault(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker", "ghcr"}, opened.ProfileNames())
+	profile, ok := opened.Profile("ghcr")
+	assert.True(t, ok)
+	assert.Equal(t, "s3cr3t-token", profile.Password)
+	assert.Equal(t, "team-a", profile.Namespace)
+
+	opened.RemoveProfile("docker")
+	assert.NoError(t, opened.Save())
+	reopened, err := OpenVault(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Len(t, reopened.Profiles(), 1)
+}
+
+func TestVault_WrongPassphrase(t *testing.T) {
+	path := VaultPath(t.TempDir())
+	vault, err := CreateVault(path, "correct horse", testKDFParams)
+	assert.NoError(t, err)
+	assert.NoError(t, vault.Save())
+
+	_, err = OpenVault(path, "battery staple")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	_, err = CreateVault(path, "", testKDFParams)
+	assert.Error(t, err)
+	_, err = CreateVault(path, "pass", KDFParams{})
+	assert.Error(t, err)
+}
+
+func TestVault_TamperedHeader(t *testing.T) {
+	path := VaultPath(t.TempDir())
+	vault, err := CreateVault(path, "correct horse", testKDFParams)
+	assert.NoError(t, err)
+	assert.NoError(t, vault.Save())
+
+	// weaker KDF parameters in the header must not be accepted silently
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	tampered := strings.Replace(string(content), `"memory": 1024`, `"memory": 2048`, 1)
+	assert.NotEqual(t, string(content), tampered)
+	assert.NoError(t, os.WriteFile(path, []byte(tampered), 0o600))
+
+	_, err = OpenVault(path, "correct horse")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestFileCredentialStore(t *testing.T) {
+	path := VaultPath(t.TempDir())
+	vault, err := CreateVault(path, "correct horse", testKDFParams)
+	assert.NoError(t, err)
+
+	var store CredentialStore = NewFileCredentialStore(vault)
+	assert.Contains(t, store.Name(), path)
+	assert.NoError(t, store.SaveProfile(Profile{Name: "ghcr", Registry: "ghcr.io", Username: "alice", Password: "token"}))
+
+	// saving writes the vault
+	opened, err := OpenVault(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Len(t, opened.Profiles(), 1)
+
+	assert.NoError(t, store.DeleteProfile("ghcr"))
+	profiles, err := store.Profiles()
+	assert.NoError(t, err)
+	assert.Empty(t, profiles)
+	assert.NoError(t, store.Close())
+}
+
+func TestEffectiveCredentialBackend(t *testing.T) {
+	assert.Equal(t, CredentialBackendFile, EffectiveCredentialBackend(CredentialBackendAuto, true), "an existing vault is kept")
+	assert.Equal(t, CredentialBackendAuto, EffectiveCredentialBackend(CredentialBackendAuto, false))
+	assert.Equal(t, CredentialBackendSecretService, EffectiveCredentialBackend(CredentialBackendSecretService, true))
+	assert.Equal(t, CredentialBackendFile, EffectiveCredentialBackend(CredentialBackendFile, false))
+}